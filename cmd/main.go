@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
-	"ai-interview/internal/controller"
 	"ai-interview/internal/database"
-	"ai-interview/internal/middleware"
+	"ai-interview/internal/router"
+	"ai-interview/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -45,30 +51,19 @@ func main() {
 	// 初始化基础数据
 	database.Seed()
 
+	// 启动后台协程，依赖上面初始化的数据库连接和迁移的表；收到退出信号时与HTTP服务一起停止
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// 过期面试的后台清理
+	service.NewInterviewService().StartExpirySweeper(ctx)
+	// 后台任务执行者，处理上传的简历
+	service.NewTaskWorker().Start(ctx)
+
 	// 设置gin模式
 	gin.SetMode(viper.GetString("server.mode"))
 
-	// 初始化路由
-	r := gin.Default()
-
-	// 注册中间件
-	r.Use(middleware.Cors())
-	r.Use(middleware.Logger())
-
-	// 注册路由
-	registerRoutes(r)
-
-	// 启动服务器
-	host := viper.GetString("server.host")
-	port := viper.GetString("server.port")
-	addr := fmt.Sprintf("%s:%s", host, port)
-	log.Printf("Server starting at http://%s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-}
-
-func registerRoutes(r *gin.Engine) {
+	// 初始化路由，与后台协程运行在同一进程中
+	r := router.SetupRouter()
 	// 健康检查
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -76,19 +71,24 @@ func registerRoutes(r *gin.Engine) {
 		})
 	})
 
-	// 创建控制器
-	resumeController := controller.NewResumeController()
-
-	// 简历相关路由
-	resumeGroup := r.Group("/api/resumes")
-	{
-		// 上传简历
-		resumeGroup.POST("/upload", resumeController.UploadResume)
-		// 获取简历信息
-		resumeGroup.GET("/:id", resumeController.GetResume)
-		// 获取用户的所有简历
-		resumeGroup.GET("/user", resumeController.GetUserResumes)
-		// 删除简历
-		resumeGroup.DELETE("/:id", resumeController.DeleteResume)
+	// 启动服务器
+	host := viper.GetString("server.host")
+	port := viper.GetString("server.port")
+	addr := fmt.Sprintf("%s:%s", host, port)
+	server := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		log.Printf("Server starting at http://%s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// 等待退出信号，处理完进行中的请求后关闭
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
 	}
 }
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"ai-interview/internal/model"
+	"ai-interview/internal/service"
+	"ai-interview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FitController 简历与岗位匹配度控制器
+type FitController struct {
+	fitService *service.FitService
+}
+
+// NewFitController 创建新的匹配度控制器
+func NewFitController() *FitController {
+	return &FitController{
+		fitService: service.NewFitService(),
+	}
+}
+
+// AnalyzeFit 分析简历与岗位的匹配度，可指定已保存的岗位或直接粘贴岗位描述
+func (c *FitController) AnalyzeFit(ctx *gin.Context) {
+	// 获取简历ID
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	var req struct {
+		JobPostingID uint                     `json:"job_posting_id"`
+		JobPosting   *service.JobPostingInput `json:"job_posting"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil || (req.JobPostingID == 0 && req.JobPosting == nil) {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "请指定 job_posting_id 或提供 job_posting")
+		return
+	}
+
+	userID := ctx.GetUint("user_id")
+	var report *model.FitReport
+	if req.JobPostingID > 0 {
+		report, err = c.fitService.Analyze(ctx.Request.Context(), userID, uint(resumeID), req.JobPostingID)
+	} else {
+		report, err = c.fitService.AnalyzeJobText(ctx.Request.Context(), userID, uint(resumeID), *req.JobPosting)
+	}
+	if err != nil {
+		jobError(ctx, "分析匹配度失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"report": report,
+	})
+}
+
+// GetFitReports 获取简历的匹配度报告，可按 job_posting_id 筛选
+func (c *FitController) GetFitReports(ctx *gin.Context) {
+	// 获取简历ID
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	var jobPostingID uint64
+	if v := ctx.Query("job_posting_id"); v != "" {
+		if jobPostingID, err = strconv.ParseUint(v, 10, 32); err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的岗位ID")
+			return
+		}
+	}
+
+	reports, err := c.fitService.GetReports(ctx.GetUint("user_id"), uint(resumeID), uint(jobPostingID))
+	if err != nil {
+		serviceError(ctx, "获取匹配度报告失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"reports": reports,
+	})
+}
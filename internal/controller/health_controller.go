@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"ai-interview/internal/service"
+	"ai-interview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthController 健康检查控制器
+type HealthController struct{}
+
+// NewHealthController 创建新的健康检查控制器
+func NewHealthController() *HealthController {
+	return &HealthController{}
+}
+
+// Health 获取服务健康状态，大模型熔断时状态为 degraded
+func (c *HealthController) Health(ctx *gin.Context) {
+	llmHealth := service.LLMHealth()
+
+	utils.SuccessResponse(ctx, gin.H{
+		"status": llmHealth.Status,
+		"llm":    llmHealth,
+	})
+}
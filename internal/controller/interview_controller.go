@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"ai-interview/internal/model"
 	"ai-interview/internal/service"
+	"ai-interview/pkg/llm"
 	"ai-interview/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -31,16 +35,16 @@ func (c *InterviewController) GenerateQuestions(ctx *gin.Context) {
 		return
 	}
 
-	// 获取面试类型
-	interviewType := ctx.Query("type")
-	if interviewType == "" {
-		interviewType = "technical" // 默认为技术面试
+	// 获取面试类型、目标岗位和计时参数
+	opts, ok := generateQuery(ctx)
+	if !ok {
+		return
 	}
 
 	// 生成面试题
-	questions, err := c.interviewService.GenerateQuestions(uint(resumeID), interviewType)
+	questions, err := c.interviewService.GenerateQuestions(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID), opts)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "生成面试题失败: "+err.Error())
+		serviceError(ctx, "生成面试题失败", err)
 		return
 	}
 
@@ -62,9 +66,9 @@ func (c *InterviewController) EvaluateAnswer(ctx *gin.Context) {
 	}
 
 	// 评估答案
-	evaluation, err := c.interviewService.EvaluateAnswer(req.QuestionID, req.Answer)
+	evaluation, err := c.interviewService.EvaluateAnswer(ctx.Request.Context(), ctx.GetUint("user_id"), req.QuestionID, req.Answer)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "评估答案失败: "+err.Error())
+		serviceError(ctx, "评估答案失败", err)
 		return
 	}
 
@@ -83,9 +87,9 @@ func (c *InterviewController) GenerateFeedback(ctx *gin.Context) {
 	}
 
 	// 生成反馈
-	feedback, err := c.interviewService.GenerateFeedback(uint(resumeID))
+	feedback, err := c.interviewService.GenerateFeedback(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "生成反馈失败: "+err.Error())
+		serviceError(ctx, "生成反馈失败", err)
 		return
 	}
 
@@ -104,9 +108,9 @@ func (c *InterviewController) GetInterviewHistory(ctx *gin.Context) {
 	}
 
 	// 获取面试历史
-	history, err := c.interviewService.GetInterviewHistory(uint(resumeID))
+	history, err := c.interviewService.GetInterviewHistory(ctx.GetUint("user_id"), uint(resumeID))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取面试历史失败: "+err.Error())
+		serviceError(ctx, "获取面试历史失败", err)
 		return
 	}
 
@@ -114,3 +118,287 @@ func (c *InterviewController) GetInterviewHistory(ctx *gin.Context) {
 		"history": history,
 	})
 }
+
+// GenerateQuestionsStream 以SSE流式生成面试题
+func (c *InterviewController) GenerateQuestionsStream(ctx *gin.Context) {
+	// 获取简历ID
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	// 获取面试类型、目标岗位和计时参数
+	opts, ok := generateQuery(ctx)
+	if !ok {
+		return
+	}
+
+	// 流式生成面试题
+	onDelta := utils.SSEStart(ctx)
+	questions, err := c.interviewService.GenerateQuestionsStream(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID), opts, onDelta)
+	if err != nil {
+		serviceError(ctx, "生成面试题失败", err)
+		return
+	}
+
+	utils.SSEDone(ctx, gin.H{
+		"questions": questions,
+	})
+}
+
+// EvaluateAnswerStream 以SSE流式评估答案
+func (c *InterviewController) EvaluateAnswerStream(ctx *gin.Context) {
+	var req struct {
+		QuestionID uint   `json:"question_id" binding:"required"`
+		Answer     string `json:"answer" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	// 流式评估答案
+	onDelta := utils.SSEStart(ctx)
+	evaluation, err := c.interviewService.EvaluateAnswerStream(ctx.Request.Context(), ctx.GetUint("user_id"), req.QuestionID, req.Answer, onDelta)
+	if err != nil {
+		serviceError(ctx, "评估答案失败", err)
+		return
+	}
+
+	utils.SSEDone(ctx, gin.H{
+		"evaluation": evaluation,
+	})
+}
+
+// GenerateFeedbackStream 以SSE流式生成面试反馈
+func (c *InterviewController) GenerateFeedbackStream(ctx *gin.Context) {
+	// 获取简历ID
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	// 流式生成反馈
+	onDelta := utils.SSEStart(ctx)
+	feedback, err := c.interviewService.GenerateFeedbackStream(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID), onDelta)
+	if err != nil {
+		serviceError(ctx, "生成反馈失败", err)
+		return
+	}
+
+	utils.SSEDone(ctx, gin.H{
+		"feedback": feedback,
+	})
+}
+
+// StartSession 开始多轮会话面试
+func (c *InterviewController) StartSession(ctx *gin.Context) {
+	// 获取简历ID
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	var req struct {
+		Type              string `json:"type"`
+		JobPostingID      uint   `json:"job_posting_id"`
+		MaxTurns          int    `json:"max_turns" binding:"omitempty,min=1,max=50"`
+		TimeBudget        int    `json:"time_budget" binding:"omitempty,min=60"`         // 总时长（秒）
+		QuestionTimeLimit int    `json:"question_time_limit" binding:"omitempty,min=10"` // 每题限时（秒）
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil && ctx.Request.ContentLength > 0 {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	turn, err := c.interviewService.StartSession(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID), service.SessionOptions{
+		Type:         req.Type,
+		MaxTurns:     req.MaxTurns,
+		JobPostingID: req.JobPostingID,
+		TimingOptions: service.TimingOptions{
+			TimeBudget:        time.Duration(req.TimeBudget) * time.Second,
+			QuestionTimeLimit: time.Duration(req.QuestionTimeLimit) * time.Second,
+		},
+	})
+	if err != nil {
+		serviceError(ctx, "开始面试失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, turn)
+}
+
+// GetSession 获取会话面试记录
+func (c *InterviewController) GetSession(ctx *gin.Context) {
+	// 获取面试ID
+	interviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的面试ID")
+		return
+	}
+
+	interview, err := c.interviewService.GetSession(ctx.GetUint("user_id"), uint(interviewID))
+	if err != nil {
+		serviceError(ctx, "获取面试记录失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"interview": interview,
+	})
+}
+
+// SubmitSessionAnswer 回答会话面试的当前问题
+func (c *InterviewController) SubmitSessionAnswer(ctx *gin.Context) {
+	// 获取面试ID
+	interviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的面试ID")
+		return
+	}
+
+	var req struct {
+		Answer string `json:"answer" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	turn, err := c.interviewService.SubmitSessionAnswer(ctx.Request.Context(), ctx.GetUint("user_id"), uint(interviewID), req.Answer)
+	if err != nil {
+		serviceError(ctx, "提交回答失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, turn)
+}
+
+// ServeQuestion 获取问题并开始计时
+func (c *InterviewController) ServeQuestion(ctx *gin.Context) {
+	// 获取问题ID
+	questionID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的问题ID")
+		return
+	}
+
+	served, err := c.interviewService.ServeQuestion(ctx.GetUint("user_id"), uint(questionID))
+	if err != nil {
+		serviceError(ctx, "获取问题失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, served)
+}
+
+// PauseInterview 暂停面试
+func (c *InterviewController) PauseInterview(ctx *gin.Context) {
+	c.changeStatus(ctx, c.interviewService.PauseInterview, "暂停面试失败")
+}
+
+// ResumeInterview 恢复面试
+func (c *InterviewController) ResumeInterview(ctx *gin.Context) {
+	c.changeStatus(ctx, c.interviewService.ResumeInterview, "恢复面试失败")
+}
+
+// FinishInterview 结束面试
+func (c *InterviewController) FinishInterview(ctx *gin.Context) {
+	c.changeStatus(ctx, c.interviewService.FinishInterview, "结束面试失败")
+}
+
+// AbandonInterview 放弃面试
+func (c *InterviewController) AbandonInterview(ctx *gin.Context) {
+	c.changeStatus(ctx, c.interviewService.AbandonInterview, "放弃面试失败")
+}
+
+// changeStatus 解析面试ID并执行状态流转
+func (c *InterviewController) changeStatus(ctx *gin.Context, change func(userID, interviewID uint) (*model.Interview, error), message string) {
+	// 获取面试ID
+	interviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的面试ID")
+		return
+	}
+
+	interview, err := change(ctx.GetUint("user_id"), uint(interviewID))
+	if err != nil {
+		serviceError(ctx, message, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"interview": interview,
+	})
+}
+
+// generateQuery 解析生成面试题的查询参数 type、job_posting_id 及计时参数 time_budget、question_time_limit（秒），参数无效时输出错误并返回 false
+func generateQuery(ctx *gin.Context) (service.GenerateOptions, bool) {
+	var query struct {
+		Type              string `form:"type"`
+		JobPostingID      uint   `form:"job_posting_id"`
+		TimeBudget        int    `form:"time_budget" binding:"omitempty,min=60"`
+		QuestionTimeLimit int    `form:"question_time_limit" binding:"omitempty,min=10"`
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return service.GenerateOptions{}, false
+	}
+	if query.Type == "" {
+		query.Type = "technical" // 默认为技术面试
+	}
+	return service.GenerateOptions{
+		Type:         query.Type,
+		JobPostingID: query.JobPostingID,
+		TimingOptions: service.TimingOptions{
+			TimeBudget:        time.Duration(query.TimeBudget) * time.Second,
+			QuestionTimeLimit: time.Duration(query.QuestionTimeLimit) * time.Second,
+		},
+	}, true
+}
+
+// serviceError 输出服务层错误：配额超限返回429及剩余配额，熔断返回503，越权返回403，资源不存在返回404，面试状态不允许返回409；SSE请求推送错误事件
+func serviceError(ctx *gin.Context, message string, err error) {
+	code := http.StatusInternalServerError
+	var data interface{}
+
+	var quotaErr *service.QuotaExceededError
+	var lateErr *service.LateAnswerError
+	switch {
+	case errors.As(err, &quotaErr):
+		code = http.StatusTooManyRequests
+		data = quotaErr
+		ctx.Header("Retry-After", strconv.Itoa(int(time.Until(quotaErr.ResetAt).Seconds())+1))
+	case errors.Is(err, llm.ErrCircuitOpen):
+		code = http.StatusServiceUnavailable
+	case errors.As(err, &lateErr):
+		code = http.StatusConflict
+		data = lateErr.Evaluation
+	case errors.Is(err, service.ErrForbidden):
+		code = http.StatusForbidden
+	case errors.Is(err, service.ErrJobPostingNotFound),
+		errors.Is(err, service.ErrResumeNotFound),
+		errors.Is(err, service.ErrProfileNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, service.ErrNotSessionInterview),
+		errors.Is(err, service.ErrInterviewFinished),
+		errors.Is(err, service.ErrInterviewPaused),
+		errors.Is(err, service.ErrInvalidTransition),
+		errors.Is(err, service.ErrNoPendingQuestion),
+		errors.Is(err, service.ErrQuestionAnswered),
+		errors.Is(err, service.ErrResumeProcessing),
+		errors.Is(err, service.ErrResumeFailed):
+		code = http.StatusConflict
+	}
+
+	message = message + ": " + err.Error()
+	if utils.IsSSE(ctx) {
+		utils.SSEError(ctx, code, message, data)
+		return
+	}
+	utils.ErrorResponseWithData(ctx, code, message, data)
+}
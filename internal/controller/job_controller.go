@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ai-interview/internal/service"
+	"ai-interview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobPostingController 岗位描述控制器
+type JobPostingController struct {
+	jobService *service.JobPostingService
+}
+
+// NewJobPostingController 创建新的岗位描述控制器
+func NewJobPostingController() *JobPostingController {
+	return &JobPostingController{
+		jobService: service.NewJobPostingService(),
+	}
+}
+
+// CreateJobPosting 创建岗位描述，可直接粘贴岗位描述原文
+func (c *JobPostingController) CreateJobPosting(ctx *gin.Context) {
+	var input service.JobPostingInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	job, err := c.jobService.Create(ctx.Request.Context(), ctx.GetUint("user_id"), input)
+	if err != nil {
+		jobError(ctx, "创建岗位描述失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"job_posting": job,
+	})
+}
+
+// GetJobPosting 获取岗位描述
+func (c *JobPostingController) GetJobPosting(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的岗位ID")
+		return
+	}
+
+	job, err := c.jobService.Get(ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		jobError(ctx, "获取岗位描述失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"job_posting": job,
+	})
+}
+
+// ListJobPostings 获取当前用户的所有岗位描述
+func (c *JobPostingController) ListJobPostings(ctx *gin.Context) {
+	jobs, err := c.jobService.List(ctx.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取岗位描述失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"job_postings": jobs,
+	})
+}
+
+// UpdateJobPosting 更新岗位描述
+func (c *JobPostingController) UpdateJobPosting(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的岗位ID")
+		return
+	}
+
+	var input service.JobPostingInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	job, err := c.jobService.Update(ctx.Request.Context(), ctx.GetUint("user_id"), uint(id), input)
+	if err != nil {
+		jobError(ctx, "更新岗位描述失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"job_posting": job,
+	})
+}
+
+// DeleteJobPosting 删除岗位描述
+func (c *JobPostingController) DeleteJobPosting(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的岗位ID")
+		return
+	}
+
+	if err := c.jobService.Delete(ctx.GetUint("user_id"), uint(id)); err != nil {
+		jobError(ctx, "删除岗位描述失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, nil)
+}
+
+// jobError 输出岗位描述相关错误，参数无效返回400，其余交给 serviceError
+func jobError(ctx *gin.Context, message string, err error) {
+	if errors.Is(err, service.ErrInvalidJobPosting) {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	serviceError(ctx, message, err)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ai-interview/internal/service"
+	"ai-interview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResumeProfileController 简历结构化信息控制器
+type ResumeProfileController struct {
+	profileService *service.ResumeProfileService
+}
+
+// NewResumeProfileController 创建新的简历结构化信息控制器
+func NewResumeProfileController() *ResumeProfileController {
+	return &ResumeProfileController{
+		profileService: service.NewResumeProfileService(),
+	}
+}
+
+// GetProfile 获取简历的结构化信息，尚未提取时返回404
+func (c *ResumeProfileController) GetProfile(ctx *gin.Context) {
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	profile, err := c.profileService.GetProfile(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID))
+	if err != nil {
+		serviceError(ctx, "获取简历信息失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"profile": profile,
+	})
+}
+
+// ExtractProfile 重新提取简历的结构化信息，会覆盖用户的修正
+func (c *ResumeProfileController) ExtractProfile(ctx *gin.Context) {
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	profile, err := c.profileService.Extract(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID))
+	if err != nil {
+		serviceError(ctx, "提取简历信息失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"profile": profile,
+	})
+}
+
+// UpdateProfile 修正简历的结构化信息，未提供的字段保持不变，提供的列表整体替换
+func (c *ResumeProfileController) UpdateProfile(ctx *gin.Context) {
+	resumeID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的简历ID")
+		return
+	}
+
+	var input service.ResumeProfileInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	profile, err := c.profileService.UpdateProfile(ctx.Request.Context(), ctx.GetUint("user_id"), uint(resumeID), input)
+	if errors.Is(err, service.ErrInvalidProfile) {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		serviceError(ctx, "更新简历信息失败", err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"profile": profile,
+	})
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"ai-interview/internal/service"
+	"ai-interview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaController 大模型配额控制器
+type QuotaController struct {
+	quotaService *service.QuotaService
+}
+
+// NewQuotaController 创建新的配额控制器
+func NewQuotaController() *QuotaController {
+	return &QuotaController{
+		quotaService: service.NewQuotaService(),
+	}
+}
+
+// GetMyQuota 获取当前用户的配额及使用情况
+func (c *QuotaController) GetMyQuota(ctx *gin.Context) {
+	report, err := c.quotaService.GetReport(ctx.GetUint("user_id"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取配额失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"quota": report,
+	})
+}
+
+// GetUserQuota 获取指定用户的配额及使用情况（管理员）
+func (c *QuotaController) GetUserQuota(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	report, err := c.quotaService.GetReport(uint(userID))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取配额失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"quota": report,
+	})
+}
+
+// SetUserQuota 为指定用户设置配额覆盖（管理员），0表示不限
+func (c *QuotaController) SetUserQuota(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	var limits service.QuotaLimits
+	if err := ctx.ShouldBindJSON(&limits); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	if limits.DailyRequests < 0 || limits.DailyTokens < 0 || limits.MonthlyRequests < 0 || limits.MonthlyTokens < 0 {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "配额不能为负数")
+		return
+	}
+
+	if err := c.quotaService.SetOverride(uint(userID), limits); err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "设置配额失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"limits": limits,
+	})
+}
+
+// DeleteUserQuota 删除指定用户的配额覆盖，恢复角色配额（管理员）
+func (c *QuotaController) DeleteUserQuota(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	if err := c.quotaService.DeleteOverride(uint(userID)); err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "删除配额失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, nil)
+}
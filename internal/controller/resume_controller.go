@@ -1,11 +1,21 @@
 package controller
 
 import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"strings"
 
+	"ai-interview/internal/model"
 	"ai-interview/internal/service"
+	"ai-interview/pkg/storage"
+	"ai-interview/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -32,46 +42,42 @@ func (c *ResumeController) UploadResume(ctx *gin.Context) {
 		return
 	}
 
-	// 获取上传的文件
-	file, err := ctx.FormFile("resume")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "请选择要上传的文件"})
+	// 获取并检查上传的文件
+	file, ok := uploadedResume(ctx)
+	if !ok {
 		return
 	}
 
-	// 检查文件大小
-	maxSize := viper.GetInt("upload.max_size") * 1024 * 1024 // 转换为字节
-	if file.Size > int64(maxSize) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "文件大小超过限制"})
+	// 上传并解析简历
+	resume, duplicate, err := c.resumeService.UploadResume(ctx.Request.Context(), userID, file)
+	if err != nil {
+		resumeError(ctx, "上传失败", err)
 		return
 	}
 
-	// 检查文件类型
-	allowedTypes := viper.GetStringSlice("upload.allowed_types")
-	fileExt := filepath.Ext(file.Filename)
-	isAllowed := false
-	for _, ext := range allowedTypes {
-		if fileExt == ext {
-			isAllowed = true
-			break
-		}
+	resumeSaved(ctx, resume, duplicate, "上传成功")
+}
+
+// CreateResumeFromText 提交粘贴的简历文本，支持纯文本、Markdown 和 HTML
+func (c *ResumeController) CreateResumeFromText(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
 	}
-	if !isAllowed {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "不支持的文件类型"})
+
+	input, ok := resumeTextInput(ctx)
+	if !ok {
 		return
 	}
 
-	// 上传并解析简历
-	resume, err := c.resumeService.UploadResume(userID, file, viper.GetString("upload.save_path"))
+	resume, duplicate, err := c.resumeService.CreateResumeFromText(ctx.Request.Context(), userID, input)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "上传失败: " + err.Error()})
+		resumeError(ctx, "保存失败", err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "上传成功",
-		"data":    resume,
-	})
+	resumeSaved(ctx, resume, duplicate, "保存成功")
 }
 
 // GetResume 获取简历信息
@@ -84,9 +90,9 @@ func (c *ResumeController) GetResume(ctx *gin.Context) {
 	}
 
 	// 获取简历信息
-	resume, err := c.resumeService.GetResume(uint(id))
+	resume, err := c.resumeService.GetResume(ctx.GetUint("user_id"), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "简历不存在"})
+		resumeError(ctx, "获取简历失败", err)
 		return
 	}
 
@@ -126,8 +132,8 @@ func (c *ResumeController) DeleteResume(ctx *gin.Context) {
 	}
 
 	// 删除简历
-	if err := c.resumeService.DeleteResume(uint(id)); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "删除简历失败"})
+	if err := c.resumeService.DeleteResume(ctx.Request.Context(), ctx.GetUint("user_id"), uint(id)); err != nil {
+		resumeError(ctx, "删除简历失败", err)
 		return
 	}
 
@@ -135,3 +141,366 @@ func (c *ResumeController) DeleteResume(ctx *gin.Context) {
 		"message": "删除成功",
 	})
 }
+
+// UploadVersion 上传简历的新版本
+func (c *ResumeController) UploadVersion(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	file, ok := uploadedResume(ctx)
+	if !ok {
+		return
+	}
+
+	resume, duplicate, err := c.resumeService.UploadResumeVersion(ctx.Request.Context(), userID, uint(id), file)
+	if err != nil {
+		resumeError(ctx, "上传失败", err)
+		return
+	}
+
+	resumeSaved(ctx, resume, duplicate, "上传成功")
+}
+
+// CreateVersionFromText 以粘贴的文本创建简历的新版本
+func (c *ResumeController) CreateVersionFromText(ctx *gin.Context) {
+	userID := ctx.GetUint("user_id")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	input, ok := resumeTextInput(ctx)
+	if !ok {
+		return
+	}
+
+	resume, duplicate, err := c.resumeService.CreateResumeVersionFromText(ctx.Request.Context(), userID, uint(id), input)
+	if err != nil {
+		resumeError(ctx, "保存失败", err)
+		return
+	}
+
+	resumeSaved(ctx, resume, duplicate, "保存成功")
+}
+
+// GetVersions 获取简历的所有版本
+func (c *ResumeController) GetVersions(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	versions, err := c.resumeService.ListVersions(ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		resumeError(ctx, "获取简历版本失败", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": versions,
+	})
+}
+
+// DiffVersions 按段落比较简历的两个版本，并返回各版本的面试得分走势。
+// 查询参数 from、to 为版本号，from 缺省时为 to 的上一个版本，to 缺省时为最新版本
+func (c *ResumeController) DiffVersions(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+	from, err := strconv.Atoi(ctx.DefaultQuery("from", "0"))
+	if err != nil || from < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的版本号"})
+		return
+	}
+	to, err := strconv.Atoi(ctx.DefaultQuery("to", "0"))
+	if err != nil || to < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的版本号"})
+		return
+	}
+
+	diff, err := c.resumeService.DiffVersions(ctx.GetUint("user_id"), uint(id), from, to)
+	if err != nil {
+		resumeError(ctx, "比较简历版本失败", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": diff,
+	})
+}
+
+// GetStatus 获取简历的处理状态，上传后状态为 processing，处理完成后为 ready 或 failed
+func (c *ResumeController) GetStatus(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	status, err := c.resumeService.GetStatus(ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		resumeError(ctx, "获取处理状态失败", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": status,
+	})
+}
+
+// StreamStatus 以SSE推送简历的处理状态，每次变化推送一个 status 事件，处理结束后关闭连接
+func (c *ResumeController) StreamStatus(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	utils.SSEStart(ctx)
+	err = c.resumeService.WatchStatus(ctx.Request.Context(), ctx.GetUint("user_id"), uint(id), func(status *service.ResumeStatus) error {
+		ctx.SSEvent(utils.SSEEventStatus, status)
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	})
+	if err != nil && ctx.Request.Context().Err() == nil {
+		if ctx.Writer.Written() {
+			utils.SSEError(ctx, http.StatusInternalServerError, "获取处理状态失败: "+err.Error(), nil)
+			return
+		}
+		ctx.Writer.Header().Del("Content-Type")
+		resumeError(ctx, "获取处理状态失败", err)
+	}
+}
+
+// GetDownloadURL 获取简历原始文件的签名下载链接
+func (c *ResumeController) GetDownloadURL(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	url, expiresAt, err := c.resumeService.DownloadURL(ctx.Request.Context(), ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		resumeError(ctx, "获取下载链接失败", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"url":        url,
+			"expires_at": expiresAt,
+		},
+	})
+}
+
+// ServeFile 通过签名下载链接下载本地存储中的文件，无需登录，由签名和有效期控制访问
+func (c *ResumeController) ServeFile(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	file, opts, err := c.resumeService.OpenSignedFile(ctx.Request.Context(), key, ctx.Request.URL.Query())
+	if errors.Is(err, storage.ErrInvalidSignature) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "下载链接无效或已过期"})
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "下载失败: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	sendFile(ctx, file, opts.FileName, opts.ContentType)
+}
+
+// DownloadResume 下载简历的原始文件，只有简历的所有者可以下载
+func (c *ResumeController) DownloadResume(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	file, resume, err := c.resumeService.OpenResumeFile(ctx.Request.Context(), ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		resumeError(ctx, "下载失败", err)
+		return
+	}
+	defer file.Close()
+
+	sendFile(ctx, file, resume.FileName, resume.ContentType)
+}
+
+// PreviewResume 以HTML页面预览解析后的简历内容，只有简历的所有者可以预览
+func (c *ResumeController) PreviewResume(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的简历ID"})
+		return
+	}
+
+	resume, body, err := c.resumeService.PreviewResume(ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		resumeError(ctx, "预览失败", err)
+		return
+	}
+
+	var page bytes.Buffer
+	if err := previewTemplate.Execute(&page, gin.H{
+		"Title":    resume.FileName,
+		"Language": resume.Language,
+		"Body":     template.HTML(body),
+	}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "预览失败: " + err.Error()})
+		return
+	}
+	// 预览内容来自用户上传的文件，禁止执行脚本和加载外部资源
+	ctx.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// previewTemplate 简历预览页面
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html{{if .Language}} lang="{{.Language}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 800px; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.6; color: #222; }
+h1, h2, h3 { border-bottom: 1px solid #eee; padding-bottom: .2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+{{.Body}}
+</body>
+</html>
+`))
+
+// sendFile 以附件形式返回文件内容，文本类型补充UTF-8编码
+func sendFile(ctx *gin.Context, file io.Reader, fileName, contentType string) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if strings.HasPrefix(contentType, "text/") && !strings.Contains(contentType, "charset") {
+		contentType += "; charset=utf-8"
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	// 上传的HTML等文件不能被浏览器按其他类型解析执行
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Status(http.StatusOK)
+	io.Copy(ctx.Writer, file)
+}
+
+// uploadedResume 获取上传的简历文件并检查大小和类型，检查未通过时已写入响应
+func uploadedResume(ctx *gin.Context) (*multipart.FileHeader, bool) {
+	file, err := ctx.FormFile("resume")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "请选择要上传的文件"})
+		return nil, false
+	}
+
+	// 检查文件大小
+	if file.Size > uploadMaxSize() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "文件大小超过限制"})
+		return nil, false
+	}
+
+	// 检查文件类型
+	allowedTypes := viper.GetStringSlice("upload.allowed_types")
+	fileExt := strings.ToLower(filepath.Ext(file.Filename))
+	for _, ext := range allowedTypes {
+		if fileExt == ext {
+			return file, true
+		}
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "不支持的文件类型"})
+	return nil, false
+}
+
+// uploadMaxSize 返回简历的大小上限（字节），配置 upload.max_size 的单位为MB
+func uploadMaxSize() int64 {
+	return viper.GetInt64("upload.max_size") * 1024 * 1024
+}
+
+// resumeTextInput 读取粘贴的简历文本，与上传文件使用相同的大小限制，检查未通过时已写入响应
+func resumeTextInput(ctx *gin.Context) (service.ResumeTextInput, bool) {
+	var input service.ResumeTextInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "请提供简历内容"})
+		return input, false
+	}
+
+	if int64(len(input.Text)) > uploadMaxSize() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "简历内容超过大小限制"})
+		return input, false
+	}
+	return input, true
+}
+
+// resumeSaved 返回保存的简历；与已有简历重复时返回已有的简历，与已有简历相似时返回保存的新版本，duplicate 为重复的类型
+func resumeSaved(ctx *gin.Context, resume *model.Resume, duplicate, message string) {
+	switch duplicate {
+	case "":
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": message,
+			"data":    resume,
+		})
+	case service.DuplicateNear:
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":   "与已有简历相似，已保存为其新版本",
+			"data":      resume,
+			"duplicate": duplicate,
+		})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":   "简历已存在，未重复保存",
+			"data":      resume,
+			"duplicate": duplicate,
+		})
+	}
+}
+
+// resumeError 按错误类型返回对应的状态码
+func resumeError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, utils.ErrFileRejected):
+		// 原因来自文件检查，不包含服务器内部信息，直接返回给用户
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrUnsupportedFormat):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "不支持的文本格式"})
+	case errors.Is(err, service.ErrEmptyResume):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "简历内容为空"})
+	case errors.Is(err, service.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrResumeProcessing), errors.Is(err, service.ErrResumeFailed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrResumeNotFound), errors.Is(err, service.ErrResumeVersionNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, storage.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "简历文件不存在"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message + ": " + err.Error()})
+	}
+}
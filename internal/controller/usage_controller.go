@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-interview/internal/repository"
+	"ai-interview/internal/service"
+	"ai-interview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 日期参数格式
+const dateLayout = "2006-01-02"
+
+// UsageController 用量统计控制器（管理员）
+type UsageController struct {
+	usageService *service.UsageService
+}
+
+// NewUsageController 创建新的用量统计控制器
+func NewUsageController() *UsageController {
+	return &UsageController{
+		usageService: service.NewUsageService(),
+	}
+}
+
+// GetUsage 按用户和按天汇总token用量及费用，支持 from、to（含当天）、user_id 过滤
+func (c *UsageController) GetUsage(ctx *gin.Context) {
+	var filter repository.UsageFilter
+
+	if from := ctx.Query("from"); from != "" {
+		t, err := time.ParseInLocation(dateLayout, from, time.Local)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的开始日期")
+			return
+		}
+		filter.From = t
+	}
+	if to := ctx.Query("to"); to != "" {
+		t, err := time.ParseInLocation(dateLayout, to, time.Local)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的结束日期")
+			return
+		}
+		filter.To = t.AddDate(0, 0, 1)
+	}
+	if userID := ctx.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的用户ID")
+			return
+		}
+		filter.UserID = uint(id)
+	}
+
+	users, err := c.usageService.GetUserTotals(filter)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取用户用量失败: "+err.Error())
+		return
+	}
+	daily, err := c.usageService.GetDailyTotals(filter)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取每日用量失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"users": users,
+		"daily": daily,
+	})
+}
+
+// GetInterviewUsage 获取单场面试的token用量及费用
+func (c *UsageController) GetInterviewUsage(ctx *gin.Context) {
+	interviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "无效的面试ID")
+		return
+	}
+
+	total, err := c.usageService.GetInterviewTotal(uint(interviewID))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "获取面试用量失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(ctx, gin.H{
+		"usage": total,
+	})
+}
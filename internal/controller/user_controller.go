@@ -78,7 +78,7 @@ func (c *UserController) Login(ctx *gin.Context) {
 	}
 
 	// 生成token
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	token, err := utils.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "生成token失败"})
 		return
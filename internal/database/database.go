@@ -70,6 +70,16 @@ func autoMigrate() error {
 		&model.Question{},
 		&model.Answer{},
 		&model.Feedback{},
+		&model.LLMUsage{},
+		&model.UserQuota{},
+		&model.JobPosting{},
+		&model.FitReport{},
+		&model.ResumeProfile{},
+		&model.ProfileEducation{},
+		&model.ProfileWork{},
+		&model.ProfileProject{},
+		&model.ProfileCertification{},
+		&model.Task{},
 	)
 }
 
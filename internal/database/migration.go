@@ -13,7 +13,19 @@ func Migrate() {
 		&model.User{},
 		&model.Interview{},
 		&model.Question{},
+		&model.Answer{},
+		&model.Feedback{},
 		&model.Resume{},
+		&model.LLMUsage{},
+		&model.UserQuota{},
+		&model.JobPosting{},
+		&model.FitReport{},
+		&model.ResumeProfile{},
+		&model.ProfileEducation{},
+		&model.ProfileWork{},
+		&model.ProfileProject{},
+		&model.ProfileCertification{},
+		&model.Task{},
 	).Error
 
 	if err != nil {
@@ -12,44 +12,52 @@ import (
 // Auth 认证中间件
 func Auth() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// 从请求头获取token
-		authHeader := ctx.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.ErrorResponse(ctx, http.StatusUnauthorized, "未提供认证token")
-			ctx.Abort()
+		if !authenticate(ctx) {
 			return
 		}
 
-		// 检查token格式
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			utils.ErrorResponse(ctx, http.StatusUnauthorized, "token格式错误")
-			ctx.Abort()
-			return
-		}
+		ctx.Next()
+	}
+}
 
-		// 解析token
-		claims, err := utils.ParseToken(parts[1])
-		if err != nil {
-			utils.ErrorResponse(ctx, http.StatusUnauthorized, "无效的token: "+err.Error())
-			ctx.Abort()
-			return
-		}
+// authenticate 校验token并将用户信息存储到上下文，失败时中止请求
+func authenticate(ctx *gin.Context) bool {
+	// 从请求头获取token
+	authHeader := ctx.GetHeader("Authorization")
+	if authHeader == "" {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "未提供认证token")
+		ctx.Abort()
+		return false
+	}
 
-		// 将用户信息存储到上下文
-		ctx.Set("user_id", claims.UserID)
-		ctx.Set("username", claims.Username)
+	// 检查token格式
+	parts := strings.SplitN(authHeader, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "token格式错误")
+		ctx.Abort()
+		return false
+	}
 
-		ctx.Next()
+	// 解析token
+	claims, err := utils.ParseToken(parts[1])
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, "无效的token: "+err.Error())
+		ctx.Abort()
+		return false
 	}
+
+	// 将用户信息存储到上下文
+	ctx.Set("user_id", claims.UserID)
+	ctx.Set("username", claims.Username)
+	ctx.Set("role", claims.Role)
+	return true
 }
 
 // AdminAuth 管理员认证中间件
 func AdminAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 先进行普通认证
-		Auth()(c)
-		if c.IsAborted() {
+		// 先进行普通认证，不能直接调用 Auth()，否则后续处理函数会在权限检查前执行
+		if !authenticate(c) {
 			return
 		}
 
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// 职级匹配结论
+const (
+	SeniorityBelow = "below" // 低于岗位要求
+	SeniorityMatch = "match" // 符合岗位要求
+	SeniorityAbove = "above" // 高于岗位要求
+)
+
+// FitReport 简历与岗位的匹配度报告
+type FitReport struct {
+	gorm.Model
+	UserID            uint       `json:"user_id" gorm:"index"`
+	ResumeID          uint       `json:"resume_id" gorm:"index"`
+	JobPostingID      uint       `json:"job_posting_id" gorm:"index"`
+	MatchedSkills     StringList `json:"matched_skills" gorm:"type:text"`
+	MissingSkills     StringList `json:"missing_skills" gorm:"type:text"`
+	KeywordScore      int        `json:"keyword_score"` // 关键词匹配得分（0-100）
+	LLMScore          int        `json:"llm_score"`     // 大模型评估得分（0-100）
+	FitScore          int        `json:"fit_score"`     // 综合匹配得分（0-100）
+	YearsOfExp        int        `json:"years_of_exp"`  // 从简历中识别的工作年限，0表示未识别
+	SeniorityFit      string     `json:"seniority_fit"` // 职级匹配：below, match, above
+	Rationale         string     `json:"rationale" gorm:"type:text"`
+	InjectionDetected bool       `json:"injection_detected"` // 简历疑似包含试图影响评估的内容，得分已被限制
+}
+
+// TableName 指定表名
+func (FitReport) TableName() string {
+	return "fit_reports"
+}
@@ -1,42 +1,117 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// 面试模式
+const (
+	InterviewModeBatch   = "batch"   // 一次性生成全部问题
+	InterviewModeSession = "session" // 多轮会话，逐题提问并追问
+)
+
+// 面试状态
+const (
+	InterviewStatusCreated    = "created"     // 已创建，尚未作答
+	InterviewStatusInProgress = "in_progress" // 进行中
+	InterviewStatusPaused     = "paused"      // 已暂停
+	InterviewStatusCompleted  = "completed"   // 已完成
+	InterviewStatusAbandoned  = "abandoned"   // 用户主动放弃
+	InterviewStatusExpired    = "expired"     // 长时间无操作，被自动过期
+)
+
+// interviewTransitions 面试状态允许的流转
+var interviewTransitions = map[string][]string{
+	InterviewStatusCreated:    {InterviewStatusInProgress, InterviewStatusCompleted, InterviewStatusAbandoned, InterviewStatusExpired},
+	InterviewStatusInProgress: {InterviewStatusPaused, InterviewStatusCompleted, InterviewStatusAbandoned, InterviewStatusExpired},
+	InterviewStatusPaused:     {InterviewStatusInProgress, InterviewStatusCompleted, InterviewStatusAbandoned, InterviewStatusExpired},
+}
+
+// 问题类型
+const (
+	QuestionKindMain     = "main"      // 新话题的主问题
+	QuestionKindFollowUp = "follow_up" // 针对上一轮回答的追问
+)
+
 // Interview 面试记录
 type Interview struct {
 	gorm.Model
-	ResumeID  uint       `json:"resume_id" gorm:"index"`
-	Type      string     `json:"type"`   // 面试类型：technical, behavioral, etc.
-	Status    string     `json:"status"` // 面试状态：pending, completed, etc.
-	Questions []Question `json:"questions" gorm:"foreignKey:InterviewID"`
+	ResumeID          uint   `json:"resume_id" gorm:"index"`
+	JobPostingID      uint   `json:"job_posting_id" gorm:"index"` // 目标岗位，0表示不针对具体岗位
+	Type              string `json:"type"`                        // 面试类型：technical, behavioral, etc.
+	Status            string `json:"status" gorm:"index"`         // 面试状态：created, in_progress, paused, completed, abandoned, expired
+	Mode              string `json:"mode"`
+	MaxTurns          int    `json:"max_turns"`           // 会话模式下的最大轮数
+	TimeBudget        int    `json:"time_budget"`         // 总时长（秒），不含暂停时长，0表示不限
+	QuestionTimeLimit int    `json:"question_time_limit"` // 每题限时（秒），0表示不限
+	// 各状态流转的时间
+	StartedAt    *time.Time `json:"started_at"`
+	PausedAt     *time.Time `json:"paused_at"`
+	ResumedAt    *time.Time `json:"resumed_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	AbandonedAt  *time.Time `json:"abandoned_at"`
+	ExpiredAt    *time.Time `json:"expired_at"`
+	EndedAt      *time.Time `json:"ended_at"`                    // 进入任一终止状态的时间
+	PausedTime   int        `json:"paused_time"`                 // 累计暂停时长（秒），不计入总时长
+	LastActiveAt *time.Time `json:"last_active_at" gorm:"index"` // 最后一次操作时间，用于判断过期
+	Questions    []Question `json:"questions" gorm:"foreignKey:InterviewID"`
+}
+
+// CanTransition 判断面试能否从当前状态流转到目标状态
+func (i *Interview) CanTransition(to string) bool {
+	for _, next := range interviewTransitions[i.Status] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished 判断面试是否已处于终止状态
+func (i *Interview) IsFinished() bool {
+	return len(interviewTransitions[i.Status]) == 0
 }
 
 // Question 面试问题
 type Question struct {
 	gorm.Model
-	InterviewID        uint    `json:"interview_id" gorm:"index"`
-	Question           string  `json:"question"`
-	EvaluationCriteria string  `json:"evaluation_criteria"`
-	Difficulty         string  `json:"difficulty"`
-	Answer             *Answer `json:"answer" gorm:"foreignKey:QuestionID"`
+	InterviewID        uint       `json:"interview_id" gorm:"index"`
+	Turn               int        `json:"turn"` // 会话模式下的轮次，从1开始
+	Kind               string     `json:"kind"`
+	ParentID           uint       `json:"parent_id"` // 追问所针对的问题
+	Question           string     `json:"question"`
+	EvaluationCriteria string     `json:"evaluation_criteria"`
+	Difficulty         string     `json:"difficulty"`
+	TimeLimit          int        `json:"time_limit"` // 限时（秒），0表示不限
+	ServedAt           *time.Time `json:"served_at"`  // 下发给候选人的时间，恢复暂停时顺延
+	Answer             *Answer    `json:"answer" gorm:"foreignKey:QuestionID"`
 }
 
 // Answer 面试答案
 type Answer struct {
 	gorm.Model
-	QuestionID uint   `json:"question_id" gorm:"index"`
+	QuestionID uint   `json:"question_id" gorm:"unique_index"` // 每个问题只有一个答案
 	Content    string `json:"content"`
 	Score      int    `json:"score"`
 	Feedback   string `json:"feedback"`
+	// 作答计时
+	SubmittedAt *time.Time `json:"submitted_at"`
+	ElapsedTime int        `json:"elapsed_time"` // 本题用时（秒）
+	Late        bool       `json:"late"`         // 是否超过本题限时或面试总时长
+	// 提示词注入检测
+	InjectionDetected bool `json:"injection_detected"` // 疑似包含试图影响评分的内容，得分已被限制
 }
 
 // Evaluation 评估结果
 type Evaluation struct {
-	Score       int      `json:"score"`
-	Evaluation  string   `json:"evaluation"`
-	Suggestions []string `json:"suggestions"`
+	Score             int      `json:"score"`
+	Evaluation        string   `json:"evaluation"`
+	Suggestions       []string `json:"suggestions"`
+	ElapsedTime       int      `json:"elapsed_time"`
+	Late              bool     `json:"late"`
+	InjectionDetected bool     `json:"injection_detected"` // 疑似包含试图影响评分的内容，得分已被限制
 }
 
 // Feedback 面试反馈
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// JobPosting 岗位描述
+type JobPosting struct {
+	gorm.Model
+	UserID         uint       `json:"user_id" gorm:"index"`
+	Title          string     `json:"title"`
+	Company        string     `json:"company"`
+	Level          string     `json:"level"` // 职级：junior, mid, senior, lead 等
+	Description    string     `json:"description" gorm:"type:text"`
+	RequiredSkills StringList `json:"required_skills" gorm:"type:text"`
+}
+
+// TableName 指定表名
+func (JobPosting) TableName() string {
+	return "job_postings"
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ResumeProfile 从简历中提取的结构化信息，每份简历一条
+type ResumeProfile struct {
+	gorm.Model
+	UserID          uint                   `json:"user_id" gorm:"index"`
+	ResumeID        uint                   `json:"resume_id" gorm:"unique_index"`
+	Name            string                 `json:"name"`
+	Phone           string                 `json:"phone"`
+	Email           string                 `json:"email"`
+	Location        string                 `json:"location"`
+	Headline        string                 `json:"headline"` // 求职意向或当前职位
+	Summary         string                 `json:"summary" gorm:"type:text"`
+	Skills          StringList             `json:"skills" gorm:"type:text"`
+	EditedAt        *time.Time             `json:"edited_at"` // 用户最后一次修正的时间，为空表示未修正
+	Educations      []ProfileEducation     `json:"educations" gorm:"foreignKey:ProfileID"`
+	WorkExperiences []ProfileWork          `json:"work_experiences" gorm:"foreignKey:ProfileID"`
+	Projects        []ProfileProject       `json:"projects" gorm:"foreignKey:ProfileID"`
+	Certifications  []ProfileCertification `json:"certifications" gorm:"foreignKey:ProfileID"`
+}
+
+// ProfileEducation 教育经历，日期格式为 YYYY-MM 或 YYYY
+type ProfileEducation struct {
+	gorm.Model
+	ProfileID   uint   `json:"profile_id" gorm:"index"`
+	School      string `json:"school"`
+	Degree      string `json:"degree"`
+	Major       string `json:"major"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	Description string `json:"description" gorm:"type:text"`
+}
+
+// ProfileWork 工作经历，EndDate 为空且 Current 为 true 表示至今
+type ProfileWork struct {
+	gorm.Model
+	ProfileID   uint   `json:"profile_id" gorm:"index"`
+	Company     string `json:"company"`
+	Title       string `json:"title"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	Current     bool   `json:"current"`
+	Description string `json:"description" gorm:"type:text"`
+}
+
+// ProfileProject 项目经历
+type ProfileProject struct {
+	gorm.Model
+	ProfileID    uint       `json:"profile_id" gorm:"index"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role"`
+	StartDate    string     `json:"start_date"`
+	EndDate      string     `json:"end_date"`
+	Technologies StringList `json:"technologies" gorm:"type:text"`
+	Description  string     `json:"description" gorm:"type:text"`
+}
+
+// ProfileCertification 证书与资质
+type ProfileCertification struct {
+	gorm.Model
+	ProfileID uint   `json:"profile_id" gorm:"index"`
+	Name      string `json:"name"`
+	Issuer    string `json:"issuer"`
+	Date      string `json:"date"`
+}
+
+// TableName 指定表名
+func (ResumeProfile) TableName() string {
+	return "resume_profiles"
+}
+
+// TableName 指定表名
+func (ProfileEducation) TableName() string {
+	return "profile_educations"
+}
+
+// TableName 指定表名
+func (ProfileWork) TableName() string {
+	return "profile_works"
+}
+
+// TableName 指定表名
+func (ProfileProject) TableName() string {
+	return "profile_projects"
+}
+
+// TableName 指定表名
+func (ProfileCertification) TableName() string {
+	return "profile_certifications"
+}
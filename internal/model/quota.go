@@ -0,0 +1,20 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// UserQuota 管理员为单个用户设置的大模型配额，存在时覆盖角色配额，0表示不限
+type UserQuota struct {
+	gorm.Model
+	UserID          uint  `json:"user_id" gorm:"unique_index"`
+	DailyRequests   int64 `json:"daily_requests"`
+	DailyTokens     int64 `json:"daily_tokens"`
+	MonthlyRequests int64 `json:"monthly_requests"`
+	MonthlyTokens   int64 `json:"monthly_tokens"`
+}
+
+// TableName 指定表名
+func (UserQuota) TableName() string {
+	return "user_quotas"
+}
@@ -3,17 +3,36 @@ package model
 import (
 	"time"
 
+	"ai-interview/pkg/utils"
+
 	"gorm.io/gorm"
 )
 
+// 简历处理状态
+const (
+	ResumeStatusProcessing = "processing" // 上传后等待后台解析和处理
+	ResumeStatusReady      = "ready"      // 处理完成，可以使用
+	ResumeStatusFailed     = "failed"     // 处理失败，原因见处理任务
+)
+
 // Resume 简历模型
 type Resume struct {
 	gorm.Model
-	UserID   uint   `json:"user_id" gorm:"index"`
-	FileName string `json:"file_name"`
-	FilePath string `json:"file_path"`
-	FileSize int64  `json:"file_size"`
-	Content  string `json:"content" gorm:"type:text"`
+	UserID      uint            `json:"user_id" gorm:"index"`
+	RootID      uint            `json:"root_id" gorm:"index"` // 所属简历第一个版本的ID，0表示本身就是第一个版本
+	Version     int             `json:"version" gorm:"default:1"`
+	FileName    string          `json:"file_name"`
+	FileKey     string          `json:"-"` // 原始文件在文件存储中的键，不返回给客户端
+	FileSize    int64           `json:"file_size"`
+	FileHash    string          `json:"-" gorm:"size:64;index"`              // 原始文件的SHA-256，用于识别重复上传
+	Fingerprint string          `json:"-" gorm:"size:64;index"`              // 规范化文本的指纹，用于识别内容相同的不同文件
+	SimHash     string          `json:"-" gorm:"size:16"`                    // 规范化文本的 SimHash，用于识别少量修改后的相似简历
+	DuplicateOf uint            `json:"duplicate_of"`                        // 内容与该简历相同或相近，0表示没有发现重复
+	ContentType string          `json:"content_type"`                        // 按文件内容识别的类型
+	Language    string          `json:"language"`                            // 简历语言：zh、en 或 zh-en（中英双语）
+	Status      string          `json:"status" gorm:"index;default:'ready'"` // 处理状态：processing、ready 或 failed
+	Content     string          `json:"content" gorm:"type:text"`            // 纯文本内容
+	Document    *utils.Document `json:"document" gorm:"type:longtext"`       // 保留标题、列表和表格的结构化内容
 }
 
 // TableName 指定表名
@@ -21,6 +40,14 @@ func (Resume) TableName() string {
 	return "resumes"
 }
 
+// LogicalID 返回简历各版本共用的ID，即第一个版本的ID
+func (r *Resume) LogicalID() uint {
+	if r.RootID != 0 {
+		return r.RootID
+	}
+	return r.ID
+}
+
 // BeforeCreate 创建前的钩子
 func (r *Resume) BeforeCreate(tx *gorm.DB) error {
 	r.CreatedAt = time.Now()
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 后台任务类型
+const (
+	TaskTypeProcessResume = "process_resume" // 解析上传的简历并完成后续处理
+)
+
+// 后台任务状态
+const (
+	TaskStatusPending   = "pending"   // 等待执行，包括等待重试
+	TaskStatusRunning   = "running"   // 执行中
+	TaskStatusSucceeded = "succeeded" // 已完成
+	TaskStatusFailed    = "failed"    // 重试次数用尽后失败
+)
+
+// Task 后台任务，保存在数据库中，服务重启后未完成的任务会继续执行
+type Task struct {
+	gorm.Model
+	Type        string     `json:"type" gorm:"index"`
+	ResumeID    uint       `json:"resume_id" gorm:"index"`
+	Status      string     `json:"status" gorm:"index"`
+	Stage       string     `json:"stage"`                  // 当前或最后执行的阶段
+	Attempts    int        `json:"attempts"`               // 已执行次数
+	MaxAttempts int        `json:"max_attempts"`           // 最多执行次数
+	Error       string     `json:"error" gorm:"type:text"` // 最近一次失败的原因
+	RunAt       time.Time  `json:"run_at" gorm:"index"`    // 最早执行时间，失败重试时顺延
+	LockedUntil *time.Time `json:"locked_until"`           // 执行租约的到期时间，到期未完成视为执行中断，可被重新领取
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// TableName 指定表名
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// IsFinished 任务是否已结束
+func (t *Task) IsFinished() bool {
+	return t.Status == TaskStatusSucceeded || t.Status == TaskStatusFailed
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// 大模型调用的业务操作
+const (
+	UsageOpGenerateQuestions = "generate_questions"
+	UsageOpEvaluateAnswer    = "evaluate_answer"
+	UsageOpGenerateFeedback  = "generate_feedback"
+	UsageOpNextQuestion      = "next_question"
+	UsageOpParseJobPosting   = "parse_job_posting"
+	UsageOpFitAnalysis       = "fit_analysis"
+	UsageOpExtractProfile    = "extract_profile"
+)
+
+// LLMUsage 大模型调用的token用量及费用
+type LLMUsage struct {
+	gorm.Model
+	UserID           uint    `json:"user_id" gorm:"index"`
+	ResumeID         uint    `json:"resume_id" gorm:"index"`
+	InterviewID      uint    `json:"interview_id" gorm:"index"`
+	QuestionID       uint    `json:"question_id" gorm:"index"`
+	Operation        string  `json:"operation"`
+	Provider         string  `json:"provider"`
+	ModelName        string  `json:"model"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // 按调用时的价格表计算
+}
+
+// TableName 指定表名
+func (LLMUsage) TableName() string {
+	return "llm_usages"
+}
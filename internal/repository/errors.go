@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry MySQL 违反唯一约束的错误码
+const mysqlDuplicateEntry = 1062
+
+// IsDuplicateKey 判断错误是否为违反唯一约束，用于识别并发写入同一条唯一记录
+func IsDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+)
+
+// FitReportRepository 匹配度报告仓库
+type FitReportRepository struct{}
+
+// NewFitReportRepository 创建新的匹配度报告仓库
+func NewFitReportRepository() *FitReportRepository {
+	return &FitReportRepository{}
+}
+
+// Create 创建匹配度报告
+func (r *FitReportRepository) Create(report *model.FitReport) error {
+	return database.DB.Create(report).Error
+}
+
+// GetByResumeID 获取简历的匹配度报告，jobPostingID 不为0时只返回该岗位的报告
+func (r *FitReportRepository) GetByResumeID(resumeID, jobPostingID uint) ([]model.FitReport, error) {
+	var reports []model.FitReport
+	db := database.DB.Where("resume_id = ?", resumeID)
+	if jobPostingID > 0 {
+		db = db.Where("job_posting_id = ?", jobPostingID)
+	}
+	err := db.Order("created_at DESC").Find(&reports).Error
+	return reports, err
+}
+
+// DeleteByResumeIDs 删除简历的匹配度报告
+func (r *FitReportRepository) DeleteByResumeIDs(resumeIDs []uint) error {
+	return database.DB.Where("resume_id IN (?)", resumeIDs).Delete(&model.FitReport{}).Error
+}
@@ -1,10 +1,22 @@
 package repository
 
 import (
+	"time"
+
 	"ai-interview/internal/database"
 	"ai-interview/internal/model"
+
+	"github.com/jinzhu/gorm"
 )
 
+// ResumeScore 一份简历的面试得分汇总
+type ResumeScore struct {
+	ResumeID     uint    `json:"resume_id"`
+	Interviews   int     `json:"interviews"`    // 面试次数
+	Answers      int     `json:"answers"`       // 已作答题数
+	AverageScore float64 `json:"average_score"` // 已作答题目的平均得分
+}
+
 // InterviewRepository 面试仓库
 type InterviewRepository struct{}
 
@@ -21,21 +33,57 @@ func (r *InterviewRepository) Create(interview *model.Interview) error {
 // GetByID 根据ID获取面试记录
 func (r *InterviewRepository) GetByID(id uint) (*model.Interview, error) {
 	var interview model.Interview
-	err := database.DB.Preload("Questions").Preload("Questions.Answer").First(&interview, id).Error
+	err := database.DB.Preload("Questions", orderByID).Preload("Questions.Answer").First(&interview, id).Error
 	return &interview, err
 }
 
+// UpdateFields 更新面试记录的指定字段
+func (r *InterviewRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Interview{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// UpdateStatus 仅当面试仍处于 from 状态时更新字段，返回是否更新成功，避免并发流转相互覆盖
+func (r *InterviewRepository) UpdateStatus(id uint, from string, fields map[string]interface{}) (bool, error) {
+	result := database.DB.Model(&model.Interview{}).Where("id = ? AND status = ?", id, from).Updates(fields)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExpireStale 将处于指定状态且最后操作时间早于 before 的面试标记为过期，返回过期数量
+func (r *InterviewRepository) ExpireStale(statuses []string, before, now time.Time) (int64, error) {
+	result := database.DB.Model(&model.Interview{}).
+		Where("status IN (?) AND COALESCE(last_active_at, updated_at) < ?", statuses, before).
+		Updates(map[string]interface{}{
+			"status":     model.InterviewStatusExpired,
+			"expired_at": now,
+			"ended_at":   now,
+		})
+	return result.RowsAffected, result.Error
+}
+
 // GetInterviewHistory 获取面试历史
 func (r *InterviewRepository) GetInterviewHistory(resumeID uint) ([]model.Interview, error) {
 	var interviews []model.Interview
 	err := database.DB.Where("resume_id = ?", resumeID).
-		Preload("Questions").
+		Preload("Questions", orderByID).
 		Preload("Questions.Answer").
 		Order("created_at DESC").
 		Find(&interviews).Error
 	return interviews, err
 }
 
+// ScoresByResume 按简历汇总面试得分，没有面试的简历不在结果中
+func (r *InterviewRepository) ScoresByResume(resumeIDs []uint) ([]ResumeScore, error) {
+	var scores []ResumeScore
+	err := database.DB.Table("interviews").
+		Select("interviews.resume_id, COUNT(DISTINCT interviews.id) AS interviews, COUNT(answers.id) AS answers, COALESCE(AVG(answers.score), 0) AS average_score").
+		Joins("LEFT JOIN questions ON questions.interview_id = interviews.id AND questions.deleted_at IS NULL").
+		Joins("LEFT JOIN answers ON answers.question_id = questions.id AND answers.deleted_at IS NULL").
+		Where("interviews.resume_id IN (?) AND interviews.deleted_at IS NULL", resumeIDs).
+		Group("interviews.resume_id").
+		Scan(&scores).Error
+	return scores, err
+}
+
 // CreateQuestion 创建面试问题
 func (r *InterviewRepository) CreateQuestion(question *model.Question) error {
 	return database.DB.Create(question).Error
@@ -48,11 +96,26 @@ func (r *InterviewRepository) GetQuestionByID(id uint) (*model.Question, error)
 	return &question, err
 }
 
-// CreateAnswer 创建答案
+// UpdateQuestionFields 更新问题的指定字段
+func (r *InterviewRepository) UpdateQuestionFields(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Question{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// CreateAnswer 创建答案，answers.question_id 唯一，问题已有答案时返回违反唯一约束的错误
 func (r *InterviewRepository) CreateAnswer(answer *model.Answer) error {
 	return database.DB.Create(answer).Error
 }
 
+// UpdateAnswerFields 更新答案的指定字段
+func (r *InterviewRepository) UpdateAnswerFields(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Answer{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteAnswer 物理删除答案，释放唯一索引使问题可以重新作答
+func (r *InterviewRepository) DeleteAnswer(id uint) error {
+	return database.DB.Unscoped().Where("id = ?", id).Delete(&model.Answer{}).Error
+}
+
 // CreateFeedback 创建反馈
 func (r *InterviewRepository) CreateFeedback(feedback *model.Feedback) error {
 	return database.DB.Create(feedback).Error
@@ -64,3 +127,8 @@ func (r *InterviewRepository) GetFeedbackByResumeID(resumeID uint) (*model.Feedb
 	err := database.DB.Where("resume_id = ?", resumeID).First(&feedback).Error
 	return &feedback, err
 }
+
+// orderByID 预加载问题时按创建顺序排列
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
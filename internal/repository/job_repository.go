@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+)
+
+// JobPostingRepository 岗位描述仓库
+type JobPostingRepository struct{}
+
+// NewJobPostingRepository 创建新的岗位描述仓库
+func NewJobPostingRepository() *JobPostingRepository {
+	return &JobPostingRepository{}
+}
+
+// Create 创建岗位描述
+func (r *JobPostingRepository) Create(job *model.JobPosting) error {
+	return database.DB.Create(job).Error
+}
+
+// GetByID 根据ID获取岗位描述
+func (r *JobPostingRepository) GetByID(id uint) (*model.JobPosting, error) {
+	var job model.JobPosting
+	err := database.DB.First(&job, id).Error
+	return &job, err
+}
+
+// GetByUserID 获取用户的所有岗位描述
+func (r *JobPostingRepository) GetByUserID(userID uint) ([]model.JobPosting, error) {
+	var jobs []model.JobPosting
+	err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&jobs).Error
+	return jobs, err
+}
+
+// Update 更新岗位描述
+func (r *JobPostingRepository) Update(job *model.JobPosting) error {
+	return database.DB.Save(job).Error
+}
+
+// Delete 删除岗位描述
+func (r *JobPostingRepository) Delete(id uint) error {
+	return database.DB.Delete(&model.JobPosting{}, id).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ResumeProfileRepository 简历结构化信息仓库
+type ResumeProfileRepository struct{}
+
+// NewResumeProfileRepository 创建新的简历结构化信息仓库
+func NewResumeProfileRepository() *ResumeProfileRepository {
+	return &ResumeProfileRepository{}
+}
+
+// GetByResumeID 获取简历的结构化信息及其各项经历
+func (r *ResumeProfileRepository) GetByResumeID(resumeID uint) (*model.ResumeProfile, error) {
+	var profile model.ResumeProfile
+	err := database.DB.Where("resume_id = ?", resumeID).
+		Preload("Educations", orderByID).
+		Preload("WorkExperiences", orderByID).
+		Preload("Projects", orderByID).
+		Preload("Certifications", orderByID).
+		First(&profile).Error
+	return &profile, err
+}
+
+// Save 保存结构化信息，各项经历整体替换为 profile 中的内容
+func (r *ResumeProfileRepository) Save(profile *model.ResumeProfile) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if profile.ID != 0 {
+			if err := deleteProfileItems(tx, profile.ID); err != nil {
+				return err
+			}
+		}
+
+		// 清空各项经历的ID，使其在保存时重新创建
+		for i := range profile.Educations {
+			profile.Educations[i].ID = 0
+		}
+		for i := range profile.WorkExperiences {
+			profile.WorkExperiences[i].ID = 0
+		}
+		for i := range profile.Projects {
+			profile.Projects[i].ID = 0
+		}
+		for i := range profile.Certifications {
+			profile.Certifications[i].ID = 0
+		}
+		return tx.Save(profile).Error
+	})
+}
+
+// DeleteByResumeID 删除简历的结构化信息
+func (r *ResumeProfileRepository) DeleteByResumeID(resumeID uint) error {
+	var profile model.ResumeProfile
+	err := database.DB.Where("resume_id = ?", resumeID).First(&profile).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteProfileItems(tx, profile.ID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&profile).Error
+	})
+}
+
+// deleteProfileItems 删除结构化信息下的各项经历
+func deleteProfileItems(tx *gorm.DB, profileID uint) error {
+	for _, item := range []interface{}{
+		&model.ProfileEducation{},
+		&model.ProfileWork{},
+		&model.ProfileProject{},
+		&model.ProfileCertification{},
+	} {
+		if err := tx.Unscoped().Where("profile_id = ?", profileID).Delete(item).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
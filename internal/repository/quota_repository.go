@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+)
+
+// QuotaRepository 配额仓库
+type QuotaRepository struct{}
+
+// NewQuotaRepository 创建新的配额仓库
+func NewQuotaRepository() *QuotaRepository {
+	return &QuotaRepository{}
+}
+
+// GetByUserID 获取用户的配额覆盖，不存在时返回 nil
+func (r *QuotaRepository) GetByUserID(userID uint) (*model.UserQuota, error) {
+	var quota model.UserQuota
+	db := database.DB.Where("user_id = ?", userID).First(&quota)
+	if db.RecordNotFound() {
+		return nil, nil
+	}
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	return &quota, nil
+}
+
+// Save 创建或更新用户的配额覆盖
+func (r *QuotaRepository) Save(quota *model.UserQuota) error {
+	existing, err := r.GetByUserID(quota.UserID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		quota.ID = existing.ID
+		quota.CreatedAt = existing.CreatedAt
+	}
+	return database.DB.Save(quota).Error
+}
+
+// DeleteByUserID 删除用户的配额覆盖
+func (r *QuotaRepository) DeleteByUserID(userID uint) error {
+	return database.DB.Unscoped().Where("user_id = ?", userID).Delete(&model.UserQuota{}).Error
+}
+
+// GetUserRole 获取用户角色
+func (r *QuotaRepository) GetUserRole(userID uint) (string, error) {
+	var user model.User
+	err := database.DB.Select("role").First(&user, userID).Error
+	return user.Role, err
+}
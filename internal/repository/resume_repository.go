@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"ai-interview/internal/database"
 	"ai-interview/internal/model"
 )
@@ -25,14 +27,69 @@ func (r *ResumeRepository) GetByID(id uint) (*model.Resume, error) {
 	return &resume, err
 }
 
-// GetByUserID 获取用户的所有简历
+// GetByUserID 获取用户的所有简历，每份简历只返回最新版本
 func (r *ResumeRepository) GetByUserID(userID uint) ([]model.Resume, error) {
 	var resumes []model.Resume
-	err := database.DB.Where("user_id = ?", userID).Find(&resumes).Error
+	err := database.DB.Where("user_id = ?", userID).
+		Where(`NOT EXISTS (SELECT 1 FROM resumes AS newer WHERE newer.deleted_at IS NULL
+			AND newer.root_id = CASE WHEN resumes.root_id = 0 THEN resumes.id ELSE resumes.root_id END
+			AND newer.version > resumes.version)`).
+		Order("updated_at DESC").
+		Find(&resumes).Error
+	return resumes, err
+}
+
+// GetVersions 获取简历的所有版本，按版本号排列
+func (r *ResumeRepository) GetVersions(logicalID uint) ([]model.Resume, error) {
+	var resumes []model.Resume
+	err := database.DB.Where("id = ? OR root_id = ?", logicalID, logicalID).
+		Order("version ASC, id ASC").
+		Find(&resumes).Error
 	return resumes, err
 }
 
+// FindByFileHash 查找用户上传过的相同文件，不包括处理失败的简历，有多个时返回最新的
+func (r *ResumeRepository) FindByFileHash(userID uint, fileHash string) (*model.Resume, error) {
+	var resume model.Resume
+	err := database.DB.Where("user_id = ? AND file_hash = ? AND status <> ?", userID, fileHash, model.ResumeStatusFailed).
+		Order("id DESC").
+		First(&resume).Error
+	return &resume, err
+}
+
+// FindByFingerprint 查找用户文本指纹相同的其他简历，不包括处理失败的简历，有多个时返回最新的
+func (r *ResumeRepository) FindByFingerprint(userID uint, fingerprint string, excludeID uint) (*model.Resume, error) {
+	var resume model.Resume
+	err := database.DB.Where("user_id = ? AND fingerprint = ? AND id <> ? AND status <> ?",
+		userID, fingerprint, excludeID, model.ResumeStatusFailed).
+		Order("id DESC").
+		First(&resume).Error
+	return &resume, err
+}
+
+// ListSimHashes 获取用户有 SimHash 的其他简历，不包括处理失败的简历，只查询比较相似度和确定版本所需的字段
+func (r *ResumeRepository) ListSimHashes(userID, excludeID uint) ([]model.Resume, error) {
+	var resumes []model.Resume
+	err := database.DB.Select("id, user_id, root_id, version, sim_hash").
+		Where("user_id = ? AND sim_hash <> '' AND id <> ? AND status <> ?", userID, excludeID, model.ResumeStatusFailed).
+		Order("id DESC").
+		Find(&resumes).Error
+	return resumes, err
+}
+
+// UpdateFields 更新简历的指定字段。Resume 的钩子按 gorm.io 的签名定义，jinzhu/gorm 无法调用，
+// 因此按表名更新以跳过钩子，并在这里设置更新时间
+func (r *ResumeRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	fields["updated_at"] = time.Now()
+	return database.DB.Table(model.Resume{}.TableName()).Where("id = ? AND deleted_at IS NULL", id).Updates(fields).Error
+}
+
 // Delete 删除简历
 func (r *ResumeRepository) Delete(id uint) error {
 	return database.DB.Delete(&model.Resume{}, id).Error
 }
+
+// DeleteVersions 删除简历的所有版本
+func (r *ResumeRepository) DeleteVersions(logicalID uint) error {
+	return database.DB.Where("id = ? OR root_id = ?", logicalID, logicalID).Delete(&model.Resume{}).Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+)
+
+// TaskRepository 后台任务仓库
+type TaskRepository struct{}
+
+// NewTaskRepository 创建新的后台任务仓库
+func NewTaskRepository() *TaskRepository {
+	return &TaskRepository{}
+}
+
+// Create 创建后台任务
+func (r *TaskRepository) Create(task *model.Task) error {
+	return database.DB.Create(task).Error
+}
+
+// GetByID 根据ID获取后台任务
+func (r *TaskRepository) GetByID(id uint) (*model.Task, error) {
+	var task model.Task
+	err := database.DB.First(&task, id).Error
+	return &task, err
+}
+
+// GetLatestByResumeID 获取简历最近创建的某类后台任务
+func (r *TaskRepository) GetLatestByResumeID(resumeID uint, taskType string) (*model.Task, error) {
+	var task model.Task
+	err := database.DB.Where("resume_id = ? AND type = ?", resumeID, taskType).Order("id DESC").First(&task).Error
+	return &task, err
+}
+
+// ListRunnable 获取可以执行的任务：到达执行时间的等待任务，以及租约已过期的执行中任务
+func (r *TaskRepository) ListRunnable(now time.Time, limit int) ([]model.Task, error) {
+	var tasks []model.Task
+	err := database.DB.
+		Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.TaskStatusPending, now, model.TaskStatusRunning, now).
+		Order("run_at ASC, id ASC").
+		Limit(limit).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// Claim 领取任务并设置执行租约，任务已被其他执行者领取时返回 false
+func (r *TaskRepository) Claim(task *model.Task, now, lockedUntil time.Time) (bool, error) {
+	result := database.DB.Model(&model.Task{}).
+		Where("id = ? AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?))",
+			task.ID, model.TaskStatusPending, now, model.TaskStatusRunning, now).
+		Updates(map[string]interface{}{
+			"status":       model.TaskStatusRunning,
+			"attempts":     task.Attempts + 1,
+			"locked_until": lockedUntil,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	task.Status = model.TaskStatusRunning
+	task.Attempts++
+	task.LockedUntil = &lockedUntil
+	return true, nil
+}
+
+// UpdateFields 更新后台任务的指定字段
+func (r *TaskRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return database.DB.Model(&model.Task{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// DeleteByResumeIDs 删除简历的后台任务，未执行的任务不再被领取
+func (r *TaskRepository) DeleteByResumeIDs(resumeIDs []uint) error {
+	return database.DB.Where("resume_id IN (?)", resumeIDs).Delete(&model.Task{}).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"time"
+
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+
+	"github.com/jinzhu/gorm"
+)
+
+// UsageTotal 用量汇总
+type UsageTotal struct {
+	UserID           uint    `json:"user_id,omitempty"`
+	Day              string  `json:"day,omitempty"`
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// UsageFilter 用量查询条件
+type UsageFilter struct {
+	UserID uint
+	From   time.Time
+	To     time.Time
+}
+
+// 汇总字段
+const usageTotalColumns = "COUNT(*) AS requests, COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, COALESCE(SUM(completion_tokens), 0) AS completion_tokens, COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(cost), 0) AS cost"
+
+// UsageRepository 用量仓库
+type UsageRepository struct{}
+
+// NewUsageRepository 创建新的用量仓库
+func NewUsageRepository() *UsageRepository {
+	return &UsageRepository{}
+}
+
+// Create 创建用量记录
+func (r *UsageRepository) Create(usage *model.LLMUsage) error {
+	return database.DB.Create(usage).Error
+}
+
+// LinkInterview 将用量记录关联到面试
+func (r *UsageRepository) LinkInterview(ids []uint, interviewID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return database.DB.Model(&model.LLMUsage{}).Where("id IN (?)", ids).Update("interview_id", interviewID).Error
+}
+
+// SumByUser 按用户汇总用量
+func (r *UsageRepository) SumByUser(filter UsageFilter) ([]UsageTotal, error) {
+	var totals []UsageTotal
+	err := r.query(filter).
+		Select("user_id, " + usageTotalColumns).
+		Group("user_id").
+		Order("cost DESC").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// SumByDay 按天汇总用量
+func (r *UsageRepository) SumByDay(filter UsageFilter) ([]UsageTotal, error) {
+	var totals []UsageTotal
+	err := r.query(filter).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS day, " + usageTotalColumns).
+		Group("day").
+		Order("day ASC").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// Sum 汇总满足条件的用量
+func (r *UsageRepository) Sum(filter UsageFilter) (*UsageTotal, error) {
+	var total UsageTotal
+	err := r.query(filter).
+		Select(usageTotalColumns).
+		Scan(&total).Error
+	return &total, err
+}
+
+// SumByInterview 汇总单场面试的用量
+func (r *UsageRepository) SumByInterview(interviewID uint) (*UsageTotal, error) {
+	var total UsageTotal
+	err := database.DB.Model(&model.LLMUsage{}).
+		Select(usageTotalColumns).
+		Where("interview_id = ?", interviewID).
+		Scan(&total).Error
+	return &total, err
+}
+
+// query 构建带过滤条件的查询
+func (r *UsageRepository) query(filter UsageFilter) *gorm.DB {
+	db := database.DB.Model(&model.LLMUsage{})
+	if filter.UserID != 0 {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		db = db.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		db = db.Where("created_at < ?", filter.To)
+	}
+	return db
+}
@@ -19,6 +19,12 @@ func SetupRouter() *gin.Engine {
 	userController := controller.NewUserController()
 	resumeController := controller.NewResumeController()
 	interviewController := controller.NewInterviewController()
+	healthController := controller.NewHealthController()
+	usageController := controller.NewUsageController()
+	quotaController := controller.NewQuotaController()
+	jobController := controller.NewJobPostingController()
+	fitController := controller.NewFitController()
+	profileController := controller.NewResumeProfileController()
 
 	// 公开路由
 	public := r.Group("/api")
@@ -26,6 +32,12 @@ func SetupRouter() *gin.Engine {
 		// 用户认证
 		public.POST("/auth/register", userController.Register)
 		public.POST("/auth/login", userController.Login)
+
+		// 健康检查
+		public.GET("/health", healthController.Health)
+
+		// 签名下载链接（本地文件存储）
+		public.GET("/files/*key", resumeController.ServeFile)
 	}
 
 	// 需要认证的路由
@@ -35,18 +47,78 @@ func SetupRouter() *gin.Engine {
 		// 用户相关
 		authorized.GET("/user/profile", userController.GetProfile)
 		authorized.PUT("/user/profile", userController.UpdateProfile)
+		authorized.GET("/user/quota", quotaController.GetMyQuota)
 
 		// 简历相关
 		authorized.POST("/resumes/upload", resumeController.UploadResume)
+		authorized.POST("/resumes/text", resumeController.CreateResumeFromText)
 		authorized.GET("/resumes/:id", resumeController.GetResume)
 		authorized.GET("/resumes/user", resumeController.GetUserResumes)
 		authorized.DELETE("/resumes/:id", resumeController.DeleteResume)
+		authorized.GET("/resumes/:id/versions", resumeController.GetVersions)
+		authorized.POST("/resumes/:id/versions", resumeController.UploadVersion)
+		authorized.POST("/resumes/:id/versions/text", resumeController.CreateVersionFromText)
+		authorized.GET("/resumes/:id/diff", resumeController.DiffVersions)
+		authorized.GET("/resumes/:id/download-url", resumeController.GetDownloadURL)
+		authorized.GET("/resumes/:id/download", resumeController.DownloadResume)
+		authorized.GET("/resumes/:id/preview", resumeController.PreviewResume)
+		authorized.GET("/resumes/:id/status", resumeController.GetStatus)
+		authorized.GET("/resumes/:id/status/stream", resumeController.StreamStatus)
+
+		// 简历结构化信息
+		authorized.GET("/resumes/:id/profile", profileController.GetProfile)
+		authorized.POST("/resumes/:id/profile", profileController.ExtractProfile)
+		authorized.PUT("/resumes/:id/profile", profileController.UpdateProfile)
+
+		// 岗位描述
+		authorized.POST("/jobs", jobController.CreateJobPosting)
+		authorized.GET("/jobs", jobController.ListJobPostings)
+		authorized.GET("/jobs/:id", jobController.GetJobPosting)
+		authorized.PUT("/jobs/:id", jobController.UpdateJobPosting)
+		authorized.DELETE("/jobs/:id", jobController.DeleteJobPosting)
+
+		// 简历与岗位匹配度
+		authorized.POST("/resumes/:id/fit", fitController.AnalyzeFit)
+		authorized.GET("/resumes/:id/fit", fitController.GetFitReports)
 
 		// 面试相关
 		authorized.POST("/resumes/:id/interview", interviewController.GenerateQuestions)
 		authorized.POST("/interview/answer", interviewController.EvaluateAnswer)
 		authorized.GET("/resumes/:id/feedback", interviewController.GenerateFeedback)
 		authorized.GET("/resumes/:id/history", interviewController.GetInterviewHistory)
+
+		// 多轮会话面试
+		authorized.POST("/resumes/:id/sessions", interviewController.StartSession)
+		authorized.GET("/interviews/:id", interviewController.GetSession)
+		authorized.POST("/interviews/:id/answers", interviewController.SubmitSessionAnswer)
+
+		// 计时面试：获取问题时开始计时
+		authorized.GET("/questions/:id", interviewController.ServeQuestion)
+
+		// 面试状态流转
+		authorized.POST("/interviews/:id/pause", interviewController.PauseInterview)
+		authorized.POST("/interviews/:id/resume", interviewController.ResumeInterview)
+		authorized.POST("/interviews/:id/finish", interviewController.FinishInterview)
+		authorized.POST("/interviews/:id/abandon", interviewController.AbandonInterview)
+
+		// 面试相关（SSE流式输出）
+		authorized.POST("/resumes/:id/interview/stream", interviewController.GenerateQuestionsStream)
+		authorized.POST("/interview/answer/stream", interviewController.EvaluateAnswerStream)
+		authorized.GET("/resumes/:id/feedback/stream", interviewController.GenerateFeedbackStream)
+	}
+
+	// 管理员路由
+	admin := r.Group("/api/admin")
+	admin.Use(middleware.AdminAuth())
+	{
+		// 大模型用量统计
+		admin.GET("/usage", usageController.GetUsage)
+		admin.GET("/usage/interviews/:id", usageController.GetInterviewUsage)
+
+		// 用户配额覆盖
+		admin.GET("/users/:id/quota", quotaController.GetUserQuota)
+		admin.PUT("/users/:id/quota", quotaController.SetUserQuota)
+		admin.DELETE("/users/:id/quota", quotaController.DeleteUserQuota)
 	}
 
 	return r
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"ai-interview/internal/model"
+
+	"github.com/spf13/viper"
+)
+
+// 面试状态相关错误
+var (
+	ErrInvalidTransition = errors.New("当前面试状态不允许该操作")
+	ErrInterviewPaused   = errors.New("面试已暂停，请先恢复")
+)
+
+// PauseInterview 暂停面试，暂停期间不计入总时长
+func (s *InterviewService) PauseInterview(userID, interviewID uint) (*model.Interview, error) {
+	return s.changeStatus(userID, interviewID, model.InterviewStatusPaused)
+}
+
+// ResumeInterview 恢复已暂停的面试
+func (s *InterviewService) ResumeInterview(userID, interviewID uint) (*model.Interview, error) {
+	return s.changeStatus(userID, interviewID, model.InterviewStatusInProgress)
+}
+
+// FinishInterview 结束面试
+func (s *InterviewService) FinishInterview(userID, interviewID uint) (*model.Interview, error) {
+	return s.changeStatus(userID, interviewID, model.InterviewStatusCompleted)
+}
+
+// AbandonInterview 放弃面试
+func (s *InterviewService) AbandonInterview(userID, interviewID uint) (*model.Interview, error) {
+	return s.changeStatus(userID, interviewID, model.InterviewStatusAbandoned)
+}
+
+// changeStatus 检查归属后流转面试状态
+func (s *InterviewService) changeStatus(userID, interviewID uint, to string) (*model.Interview, error) {
+	interview, err := s.GetSession(userID, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(interview, to); err != nil {
+		return nil, err
+	}
+	return interview, nil
+}
+
+// transition 校验并执行状态流转，记录流转时间；状态已被并发修改时返回 ErrInvalidTransition
+func (s *InterviewService) transition(interview *model.Interview, to string) error {
+	if !interview.CanTransition(to) {
+		return fmt.Errorf("%w：%s -> %s", ErrInvalidTransition, interview.Status, to)
+	}
+
+	now := time.Now()
+	from := interview.Status
+	fields := map[string]interface{}{
+		"status":         to,
+		"last_active_at": now,
+	}
+	// 离开暂停状态时累计暂停时长，并顺延已下发未作答问题的计时
+	if from == model.InterviewStatusPaused && interview.PausedAt != nil {
+		paused := now.Sub(*interview.PausedAt).Truncate(time.Second)
+		interview.PausedTime += int(paused.Seconds())
+		fields["paused_time"] = interview.PausedTime
+		if err := s.shiftServedAt(interview, paused); err != nil {
+			return err
+		}
+	}
+	switch to {
+	case model.InterviewStatusInProgress:
+		if interview.StartedAt == nil {
+			interview.StartedAt = &now
+			fields["started_at"] = now
+		}
+		if from == model.InterviewStatusPaused {
+			interview.ResumedAt = &now
+			fields["resumed_at"] = now
+		}
+	case model.InterviewStatusPaused:
+		interview.PausedAt = &now
+		fields["paused_at"] = now
+	case model.InterviewStatusCompleted:
+		interview.CompletedAt = &now
+		fields["completed_at"] = now
+	case model.InterviewStatusAbandoned:
+		interview.AbandonedAt = &now
+		fields["abandoned_at"] = now
+	case model.InterviewStatusExpired:
+		interview.ExpiredAt = &now
+		fields["expired_at"] = now
+	}
+	if to != model.InterviewStatusInProgress && to != model.InterviewStatusPaused {
+		interview.EndedAt = &now
+		fields["ended_at"] = now
+	}
+
+	ok, err := s.interviewRepo.UpdateStatus(interview.ID, from, fields)
+	if err != nil {
+		return fmt.Errorf("更新面试状态失败: %v", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w：面试状态已被修改", ErrInvalidTransition)
+	}
+	interview.Status = to
+	interview.LastActiveAt = &now
+	return nil
+}
+
+// shiftServedAt 将已下发未作答问题的下发时间顺延，暂停时长不计入每题用时
+func (s *InterviewService) shiftServedAt(interview *model.Interview, paused time.Duration) error {
+	for i := range interview.Questions {
+		q := &interview.Questions[i]
+		if q.ServedAt == nil || q.Answer != nil {
+			continue
+		}
+		servedAt := q.ServedAt.Add(paused)
+		q.ServedAt = &servedAt
+		if err := s.interviewRepo.UpdateQuestionFields(q.ID, map[string]interface{}{"served_at": servedAt}); err != nil {
+			return fmt.Errorf("更新问题记录失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// activateInterview 作答前加载面试并检查状态
+func (s *InterviewService) activateInterview(interviewID uint) (*model.Interview, error) {
+	interview, err := s.interviewRepo.GetByID(interviewID)
+	if err != nil {
+		return nil, fmt.Errorf("获取面试记录失败: %v", err)
+	}
+	if err := s.activate(interview); err != nil {
+		return nil, err
+	}
+	return interview, nil
+}
+
+// activate 检查面试是否可以作答，首次作答时将面试置为进行中
+func (s *InterviewService) activate(interview *model.Interview) error {
+	switch {
+	case interview.IsFinished():
+		return ErrInterviewFinished
+	case interview.Status == model.InterviewStatusPaused:
+		return ErrInterviewPaused
+	case interview.Status == model.InterviewStatusCreated:
+		return s.transition(interview, model.InterviewStatusInProgress)
+	}
+	return nil
+}
+
+// answered 作答后刷新最后操作时间；一次性面试的问题全部作答后自动完成
+func (s *InterviewService) answered(interview *model.Interview, questionID uint) error {
+	if interview.Mode != model.InterviewModeSession {
+		done := 0
+		for _, q := range interview.Questions {
+			if q.Answer != nil || q.ID == questionID {
+				done++
+			}
+		}
+		if done == len(interview.Questions) {
+			return s.transition(interview, model.InterviewStatusCompleted)
+		}
+	}
+
+	now := time.Now()
+	interview.LastActiveAt = &now
+	if err := s.interviewRepo.UpdateFields(interview.ID, map[string]interface{}{"last_active_at": now}); err != nil {
+		return fmt.Errorf("更新面试记录失败: %v", err)
+	}
+	return nil
+}
+
+// StartExpirySweeper 启动后台协程，按 interview.lifecycle.sweep_interval 定期过期长时间无操作的面试
+func (s *InterviewService) StartExpirySweeper(ctx context.Context) {
+	interval := viper.GetDuration("interview.lifecycle.sweep_interval")
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := s.ExpireStale(time.Now()); err != nil {
+				log.Printf("过期面试清理失败: %v", err)
+			} else if n > 0 {
+				log.Printf("已过期%d场无操作的面试", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ExpireStale 将超过空闲时长的未开始、进行中面试和超过暂停时长的已暂停面试标记为过期
+func (s *InterviewService) ExpireStale(now time.Time) (int64, error) {
+	rules := []struct {
+		statuses []string
+		timeout  time.Duration
+	}{
+		{
+			statuses: []string{model.InterviewStatusCreated, model.InterviewStatusInProgress},
+			timeout:  viper.GetDuration("interview.lifecycle.idle_timeout"),
+		},
+		{
+			statuses: []string{model.InterviewStatusPaused},
+			timeout:  viper.GetDuration("interview.lifecycle.paused_timeout"),
+		},
+	}
+
+	var total int64
+	for _, rule := range rules {
+		if rule.timeout <= 0 {
+			continue
+		}
+		n, err := s.interviewRepo.ExpireStale(rule.statuses, now.Add(-rule.timeout), now)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"ai-interview/internal/model"
+	"ai-interview/pkg/llm"
+
+	"github.com/spf13/viper"
+)
+
+// 会话相关错误
+var (
+	ErrForbidden           = errors.New("无权访问该资源")
+	ErrNotSessionInterview = errors.New("该面试不是多轮会话面试")
+	ErrInterviewFinished   = errors.New("面试已结束")
+	ErrNoPendingQuestion   = errors.New("当前没有待回答的问题")
+	ErrQuestionAnswered    = errors.New("该问题已回答")
+)
+
+// 下一步动作
+const (
+	nextActionFollowUp    = "follow_up"
+	nextActionNewQuestion = "new_question"
+	nextActionEnd         = "end"
+)
+
+// SessionOptions 创建会话面试的参数，0表示使用配置默认值
+type SessionOptions struct {
+	Type         string
+	MaxTurns     int
+	JobPostingID uint // 目标岗位，0表示不针对具体岗位
+	TimingOptions
+}
+
+// SessionTurn 会话中一轮交互的结果
+type SessionTurn struct {
+	Interview  *model.Interview  `json:"interview"`
+	Evaluation *model.Evaluation `json:"evaluation,omitempty"`
+	Question   *model.Question   `json:"question,omitempty"` // 下一个问题，面试结束时为空
+	Deadline   *time.Time        `json:"deadline,omitempty"` // 下一个问题的作答截止时间
+	Finished   bool              `json:"finished"`
+}
+
+// StartSession 开始多轮会话面试，返回第一个问题
+func (s *InterviewService) StartSession(ctx context.Context, userID, resumeID uint, opts SessionOptions) (*SessionTurn, error) {
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if err != nil {
+		return nil, fmt.Errorf("获取简历失败: %v", err)
+	}
+	if resume.UserID != userID {
+		return nil, ErrForbidden
+	}
+	if err := checkResumeReady(resume); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.targetJob(userID, opts.JobPostingID); err != nil {
+		return nil, err
+	}
+	if opts.Type == "" {
+		opts.Type = "technical"
+	}
+	if opts.MaxTurns <= 0 {
+		opts.MaxTurns = viper.GetInt("interview.session.max_turns")
+	}
+	if opts.TimeBudget <= 0 {
+		opts.TimeBudget = viper.GetDuration("interview.session.time_budget")
+	}
+
+	now := time.Now()
+	interview := &model.Interview{
+		ResumeID:     resumeID,
+		JobPostingID: opts.JobPostingID,
+		Type:         opts.Type,
+		Status:       model.InterviewStatusCreated,
+		Mode:         model.InterviewModeSession,
+		MaxTurns:     opts.MaxTurns,
+		LastActiveAt: &now,
+	}
+	applyTiming(interview, opts.TimingOptions)
+	if err := s.interviewRepo.Create(interview); err != nil {
+		return nil, fmt.Errorf("保存面试记录失败: %v", err)
+	}
+
+	// 第一个问题生成成功后才开始面试，失败时放弃该面试，不留下没有问题的进行中面试
+	question, err := s.askNext(ctx, interview, resume)
+	if err != nil {
+		if abandonErr := s.transition(interview, model.InterviewStatusAbandoned); abandonErr != nil {
+			log.Printf("放弃面试%d失败: %v", interview.ID, abandonErr)
+		}
+		return nil, err
+	}
+	if err := s.transition(interview, model.InterviewStatusInProgress); err != nil {
+		return nil, err
+	}
+
+	return s.sessionTurn(interview.ID, nil, question)
+}
+
+// GetSession 获取会话面试的完整记录
+func (s *InterviewService) GetSession(userID, interviewID uint) (*model.Interview, error) {
+	interview, err := s.interviewRepo.GetByID(interviewID)
+	if err != nil {
+		return nil, fmt.Errorf("获取面试记录失败: %v", err)
+	}
+	if err := s.checkInterviewOwner(userID, interview); err != nil {
+		return nil, err
+	}
+	return interview, nil
+}
+
+// SubmitSessionAnswer 回答当前问题，评估后给出追问或下一个问题，达到轮数或时长上限时结束面试
+func (s *InterviewService) SubmitSessionAnswer(ctx context.Context, userID, interviewID uint, answer string) (*SessionTurn, error) {
+	interview, err := s.GetSession(userID, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Mode != model.InterviewModeSession {
+		return nil, ErrNotSessionInterview
+	}
+	if interview.IsFinished() {
+		return nil, ErrInterviewFinished
+	}
+	if interview.Status == model.InterviewStatusPaused {
+		return nil, ErrInterviewPaused
+	}
+
+	pending := pendingQuestion(interview)
+	if pending == nil {
+		return nil, ErrNoPendingQuestion
+	}
+
+	// 评估当前回答；超时被拒绝的回答按0分记录后继续下一题
+	evaluation, err := s.evaluateAnswer(ctx, userID, pending.ID, answer, nil)
+	var lateErr *LateAnswerError
+	if errors.As(err, &lateErr) {
+		evaluation, err = lateErr.Evaluation, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pending.Answer = &model.Answer{
+		QuestionID:        pending.ID,
+		Content:           answer,
+		Score:             evaluation.Score,
+		Feedback:          evaluation.Evaluation,
+		ElapsedTime:       evaluation.ElapsedTime,
+		Late:              evaluation.Late,
+		InjectionDetected: evaluation.InjectionDetected,
+	}
+
+	// 达到轮数或时长上限时结束
+	if sessionExhausted(interview, time.Now()) {
+		if err := s.finishSession(interview); err != nil {
+			return nil, err
+		}
+		return s.sessionTurn(interview.ID, evaluation, nil)
+	}
+
+	resume, err := s.resumeRepo.GetByID(interview.ResumeID)
+	if err != nil {
+		return nil, fmt.Errorf("获取简历失败: %v", err)
+	}
+	question, err := s.askNext(ctx, interview, resume)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionTurn(interview.ID, evaluation, question)
+}
+
+// askNext 基于完整对话历史让模型决定追问、换题或结束，并保存新问题；模型决定结束时返回 nil
+func (s *InterviewService) askNext(ctx context.Context, interview *model.Interview, resume *model.Resume) (*model.Question, error) {
+	ctx = withUsageScope(ctx, &usageScope{
+		Operation:   model.UsageOpNextQuestion,
+		UserID:      resume.UserID,
+		ResumeID:    resume.ID,
+		InterviewID: interview.ID,
+	})
+
+	// 检查配额
+	if err := s.quotaService.Check(resume.UserID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.targetJob(resume.UserID, interview.JobPostingID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &nextQuestionResult{first: len(interview.Questions) == 0}
+	if err := s.chatMessagesStructured(ctx, sessionMessages(interview, resumePromptText(resume), resumeLanguage(resume), job), result, nil); err != nil {
+		return nil, fmt.Errorf("生成下一个问题失败: %w", err)
+	}
+
+	if result.Action == nextActionEnd {
+		return nil, s.finishSession(interview)
+	}
+
+	now := time.Now()
+	question := &model.Question{
+		InterviewID:        interview.ID,
+		Turn:               len(interview.Questions) + 1,
+		Kind:               model.QuestionKindMain,
+		Question:           result.Question,
+		EvaluationCriteria: result.EvaluationCriteria,
+		Difficulty:         result.Difficulty,
+		TimeLimit:          interview.QuestionTimeLimit,
+		ServedAt:           &now,
+	}
+	if result.Action == nextActionFollowUp {
+		if last := lastQuestion(interview); last != nil {
+			question.Kind = model.QuestionKindFollowUp
+			question.ParentID = last.ID
+		}
+	}
+	if err := s.interviewRepo.CreateQuestion(question); err != nil {
+		return nil, fmt.Errorf("保存问题记录失败: %v", err)
+	}
+	interview.Questions = append(interview.Questions, *question)
+	return question, nil
+}
+
+// finishSession 结束会话面试
+func (s *InterviewService) finishSession(interview *model.Interview) error {
+	return s.transition(interview, model.InterviewStatusCompleted)
+}
+
+// sessionTurn 重新加载面试记录并组装本轮结果
+func (s *InterviewService) sessionTurn(interviewID uint, evaluation *model.Evaluation, question *model.Question) (*SessionTurn, error) {
+	interview, err := s.interviewRepo.GetByID(interviewID)
+	if err != nil {
+		return nil, fmt.Errorf("获取面试记录失败: %v", err)
+	}
+	turn := &SessionTurn{
+		Interview:  interview,
+		Evaluation: evaluation,
+		Question:   question,
+		Finished:   question == nil,
+	}
+	if question != nil {
+		turn.Deadline = questionDeadline(interview, question)
+	}
+	return turn, nil
+}
+
+// checkInterviewOwner 检查面试是否属于该用户
+func (s *InterviewService) checkInterviewOwner(userID uint, interview *model.Interview) error {
+	resume, err := s.resumeRepo.GetByID(interview.ResumeID)
+	if err != nil {
+		return fmt.Errorf("获取简历失败: %v", err)
+	}
+	if resume.UserID != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// sessionMessages 将简历、目标岗位和完整问答历史组织为对话消息：面试官提问为 assistant，候选人回答为 user；
+// 简历、目标岗位和回答由用户提供，各自作为一条只包含标签块的 user 消息，开始指令和评分备注单独发送
+func sessionMessages(interview *model.Interview, resumeContent, language string, job *model.JobPosting) []llm.Message {
+	system := fmt.Sprintf(`%s
+现在进行一场多轮%s面试，你每次只问一个问题。候选人简历在 <resume> 标签内，候选人的回答在 <answer> 标签内。
+%s%s
+面试规则：
+1. 第一个问题从简历中最核心的经历切入
+2. 候选人回答含糊、空泛、缺少细节或明显有误时，针对该回答追问，要求给出具体例子、数据或原理
+3. 同一话题最多追问2次，回答充分后换到简历中的其他话题
+4. 共%d轮，已问过的内容不要重复
+5. 当已充分考察候选人时可以提前结束
+
+每次只返回JSON，格式如下：
+{
+    "action": "follow_up、new_question 或 end",
+    "question": "问题内容，action 为 end 时为空",
+    "evaluation_criteria": "评分标准",
+    "difficulty": "难度等级"
+}`, guardedSystemPrompt(interviewerSystemPrompt), interview.Type, jobPromptSection(job), languagePromptSection(language), interview.MaxTurns)
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: system}}
+	if job != nil {
+		messages = append(messages, llm.Message{Role: llm.RoleUser, Content: jobPromptBlock(job)})
+	}
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: untrustedBlock("resume", resumeContent)})
+	for _, q := range interview.Questions {
+		messages = append(messages, llm.Message{Role: llm.RoleAssistant, Content: q.Question})
+		if q.Answer != nil {
+			messages = append(messages, llm.Message{Role: llm.RoleUser, Content: untrustedBlock("answer", q.Answer.Content)})
+		}
+	}
+
+	// 第一轮在简历后发送开始指令；之后在候选人最后一条回答后发送评分备注
+	last := lastQuestion(interview)
+	if last == nil {
+		return append(messages, llm.Message{Role: llm.RoleUser, Content: "请开始面试，提出第一个问题。"})
+	}
+	if last.Answer != nil {
+		elapsed := formatSeconds(last.Answer.ElapsedTime)
+		if last.Answer.Late {
+			elapsed += "，已超时"
+		}
+		if last.Answer.InjectionDetected {
+			elapsed += "，回答疑似包含试图影响评分的内容"
+		}
+		messages = append(messages, llm.Message{Role: llm.RoleUser, Content: fmt.Sprintf("（面试官备注：该回答得分%d/100，用时%s，评价：%s。已进行%d/%d轮，请决定下一步。）",
+			last.Answer.Score, elapsed, last.Answer.Feedback, len(interview.Questions), interview.MaxTurns)})
+	}
+	return messages
+}
+
+// pendingQuestion 返回最后一个尚未回答的问题
+func pendingQuestion(interview *model.Interview) *model.Question {
+	last := lastQuestion(interview)
+	if last == nil || last.Answer != nil {
+		return nil
+	}
+	return last
+}
+
+// lastQuestion 返回最后一个问题
+func lastQuestion(interview *model.Interview) *model.Question {
+	if len(interview.Questions) == 0 {
+		return nil
+	}
+	return &interview.Questions[len(interview.Questions)-1]
+}
+
+// sessionExhausted 判断会话是否已达到轮数或时长上限，暂停时长不计入
+func sessionExhausted(interview *model.Interview, now time.Time) bool {
+	if interview.MaxTurns > 0 && len(interview.Questions) >= interview.MaxTurns {
+		return true
+	}
+	if interview.TimeBudget > 0 && interview.StartedAt != nil {
+		elapsed := now.Sub(*interview.StartedAt) - time.Duration(interview.PausedTime)*time.Second
+		return elapsed >= time.Duration(interview.TimeBudget)*time.Second
+	}
+	return false
+}
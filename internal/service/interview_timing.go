@@ -0,0 +1,195 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-interview/internal/model"
+
+	"github.com/spf13/viper"
+)
+
+// 超时作答的处理策略
+const (
+	latePolicyFlag   = "flag"   // 照常评估并标记超时
+	latePolicyReject = "reject" // 不评估，按0分记录
+)
+
+// LateAnswerError 超时作答被拒绝，Evaluation 为按0分记录的评估结果
+type LateAnswerError struct {
+	Evaluation *model.Evaluation `json:"evaluation"`
+}
+
+// Error 实现error接口
+func (e *LateAnswerError) Error() string {
+	return fmt.Sprintf("作答已超时（用时%s），答案未被评估", formatSeconds(e.Evaluation.ElapsedTime))
+}
+
+// TimingOptions 面试计时参数，0表示使用配置默认值
+type TimingOptions struct {
+	TimeBudget        time.Duration // 总时长
+	QuestionTimeLimit time.Duration // 每题限时
+}
+
+// ServedQuestion 下发给候选人的问题及作答截止时间
+type ServedQuestion struct {
+	Question *model.Question `json:"question"`
+	Deadline *time.Time      `json:"deadline,omitempty"`
+}
+
+// answerTiming 一次作答的计时结果
+type answerTiming struct {
+	submittedAt time.Time
+	served      bool          // 问题是否已下发，未下发的问题不计本题用时
+	elapsed     time.Duration // 本题用时
+	limit       time.Duration // 本题限时
+	used        time.Duration // 面试已用时长，不含暂停
+	budget      time.Duration // 面试总时长
+	late        bool
+}
+
+// ServeQuestion 向候选人下发问题并开始计时，重复获取不会重新计时
+func (s *InterviewService) ServeQuestion(userID, questionID uint) (*ServedQuestion, error) {
+	question, err := s.interviewRepo.GetQuestionByID(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取问题信息失败: %v", err)
+	}
+	interview, err := s.GetSession(userID, question.InterviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.activate(interview); err != nil {
+		return nil, err
+	}
+
+	for i := range interview.Questions {
+		if interview.Questions[i].ID == questionID {
+			question = &interview.Questions[i]
+			break
+		}
+	}
+	if question.ServedAt == nil && question.Answer == nil {
+		now := time.Now()
+		question.ServedAt = &now
+		if err := s.interviewRepo.UpdateQuestionFields(question.ID, map[string]interface{}{"served_at": now}); err != nil {
+			return nil, fmt.Errorf("更新问题记录失败: %v", err)
+		}
+	}
+
+	return &ServedQuestion{
+		Question: question,
+		Deadline: questionDeadline(interview, question),
+	}, nil
+}
+
+// applyTiming 将计时参数写入面试记录，每题限时未指定时使用 interview.timing.question_time_limit
+func applyTiming(interview *model.Interview, opts TimingOptions) {
+	if opts.QuestionTimeLimit <= 0 {
+		opts.QuestionTimeLimit = viper.GetDuration("interview.timing.question_time_limit")
+	}
+	interview.TimeBudget = int(opts.TimeBudget.Seconds())
+	interview.QuestionTimeLimit = int(opts.QuestionTimeLimit.Seconds())
+}
+
+// measureAnswer 计算作答用时并判断是否超时；只有通过 ServeQuestion 下发的问题才计本题用时和限时，
+// 一次性生成的问题创建时间相同，以创建时间计时会把后面的问题误判为超时
+func measureAnswer(interview *model.Interview, question *model.Question, now time.Time) answerTiming {
+	timing := answerTiming{
+		submittedAt: now,
+		budget:      time.Duration(interview.TimeBudget) * time.Second,
+	}
+	if question.ServedAt != nil {
+		timing.served = true
+		timing.elapsed = now.Sub(*question.ServedAt)
+		timing.limit = time.Duration(question.TimeLimit) * time.Second
+	}
+	if interview.StartedAt != nil {
+		timing.used = now.Sub(*interview.StartedAt) - time.Duration(interview.PausedTime)*time.Second
+	}
+
+	// 宽限时长用于抵消网络延迟
+	grace := viper.GetDuration("interview.timing.grace")
+	timing.late = (timing.limit > 0 && timing.elapsed > timing.limit+grace) ||
+		(timing.budget > 0 && timing.used > timing.budget+grace)
+	return timing
+}
+
+// rejectLate 判断超时答案是否应被拒绝
+func (t answerTiming) rejectLate() bool {
+	return t.late && viper.GetString("interview.timing.late_policy") == latePolicyReject
+}
+
+// promptNote 生成附加到评估提示词中的用时说明
+func (t answerTiming) promptNote() string {
+	var lines []string
+	if t.served {
+		line := fmt.Sprintf("作答用时：%s", formatSeconds(int(t.elapsed.Seconds())))
+		if t.limit > 0 {
+			line += fmt.Sprintf("（本题限时%s）", formatSeconds(int(t.limit.Seconds())))
+		}
+		lines = append(lines, line)
+	}
+	if t.budget > 0 {
+		lines = append(lines, fmt.Sprintf("面试已用时：%s（总时长%s）", formatSeconds(int(t.used.Seconds())), formatSeconds(int(t.budget.Seconds()))))
+	}
+	if t.late {
+		lines = append(lines, "该答案超时提交，请在评价中指出时间把控的问题并酌情扣分。")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// answerRecord 生成带计时信息的答案记录
+func (t answerTiming) answerRecord(questionID uint, content string, evaluation *model.Evaluation) *model.Answer {
+	evaluation.ElapsedTime = int(t.elapsed.Seconds())
+	evaluation.Late = t.late
+	return &model.Answer{
+		QuestionID:        questionID,
+		Content:           content,
+		Score:             evaluation.Score,
+		Feedback:          evaluation.Evaluation,
+		SubmittedAt:       &t.submittedAt,
+		ElapsedTime:       evaluation.ElapsedTime,
+		Late:              t.late,
+		InjectionDetected: evaluation.InjectionDetected,
+	}
+}
+
+// questionDeadline 计算问题的作答截止时间，取本题限时和面试总时长中较早者；都不限或面试已暂停时返回 nil
+func questionDeadline(interview *model.Interview, question *model.Question) *time.Time {
+	if interview.Status == model.InterviewStatusPaused || question.Answer != nil {
+		return nil
+	}
+
+	var deadline *time.Time
+	if question.TimeLimit > 0 && question.ServedAt != nil {
+		t := question.ServedAt.Add(time.Duration(question.TimeLimit) * time.Second)
+		deadline = &t
+	}
+	if interview.TimeBudget > 0 && interview.StartedAt != nil {
+		t := interview.StartedAt.Add(time.Duration(interview.TimeBudget+interview.PausedTime) * time.Second)
+		if deadline == nil || t.Before(*deadline) {
+			deadline = &t
+		}
+	}
+	return deadline
+}
+
+// formatSeconds 将秒数格式化为“x分y秒”
+func formatSeconds(seconds int) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%d秒", seconds)
+	}
+	if seconds%60 == 0 {
+		return fmt.Sprintf("%d分钟", seconds/60)
+	}
+	return fmt.Sprintf("%d分%d秒", seconds/60, seconds%60)
+}
+
+// limitText 格式化时长上限，0表示不限
+func limitText(seconds int) string {
+	if seconds <= 0 {
+		return "不限"
+	}
+	return formatSeconds(seconds)
+}
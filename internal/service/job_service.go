@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ai-interview/internal/model"
+	"ai-interview/internal/repository"
+	"ai-interview/pkg/llm"
+
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/viper"
+)
+
+// 岗位描述相关错误
+var (
+	ErrJobPostingNotFound = errors.New("岗位描述不存在")
+	ErrInvalidJobPosting  = errors.New("无效的岗位描述")
+)
+
+// 招聘顾问系统提示词
+const recruiterSystemPrompt = "你是一个资深的招聘顾问，擅长分析岗位描述和评估候选人与岗位的匹配程度。"
+
+// JobPostingInput 创建或更新岗位描述的参数；提供 text 时由大模型从粘贴的原文中提取未填写的字段
+type JobPostingInput struct {
+	Title          string   `json:"title"`
+	Company        string   `json:"company"`
+	Level          string   `json:"level"`
+	Description    string   `json:"description"`
+	RequiredSkills []string `json:"required_skills"`
+	Text           string   `json:"text"` // 粘贴的岗位描述原文
+}
+
+// JobPostingService 岗位描述服务
+type JobPostingService struct {
+	jobRepo           *repository.JobPostingRepository
+	quotaService      *QuotaService
+	llm               llm.Provider
+	structuredRetries int
+}
+
+// NewJobPostingService 创建新的岗位描述服务
+func NewJobPostingService() *JobPostingService {
+	return &JobPostingService{
+		jobRepo:           repository.NewJobPostingRepository(),
+		quotaService:      NewQuotaService(),
+		llm:               newMeteredProvider(NewUsageService()),
+		structuredRetries: viper.GetInt("llm.structured_retries"),
+	}
+}
+
+// Create 创建岗位描述
+func (s *JobPostingService) Create(ctx context.Context, userID uint, input JobPostingInput) (*model.JobPosting, error) {
+	job := &model.JobPosting{UserID: userID}
+	if err := s.apply(ctx, job, input); err != nil {
+		return nil, err
+	}
+	if err := s.jobRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("保存岗位描述失败: %v", err)
+	}
+	return job, nil
+}
+
+// Get 获取用户的岗位描述
+func (s *JobPostingService) Get(userID, id uint) (*model.JobPosting, error) {
+	job, err := s.jobRepo.GetByID(id)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrJobPostingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取岗位描述失败: %v", err)
+	}
+	if job.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return job, nil
+}
+
+// List 获取用户的所有岗位描述
+func (s *JobPostingService) List(userID uint) ([]model.JobPosting, error) {
+	return s.jobRepo.GetByUserID(userID)
+}
+
+// Update 更新岗位描述，未填写的字段保持不变
+func (s *JobPostingService) Update(ctx context.Context, userID, id uint, input JobPostingInput) (*model.JobPosting, error) {
+	job, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, job, input); err != nil {
+		return nil, err
+	}
+	if err := s.jobRepo.Update(job); err != nil {
+		return nil, fmt.Errorf("更新岗位描述失败: %v", err)
+	}
+	return job, nil
+}
+
+// Delete 删除岗位描述
+func (s *JobPostingService) Delete(userID, id uint) error {
+	if _, err := s.Get(userID, id); err != nil {
+		return err
+	}
+	return s.jobRepo.Delete(id)
+}
+
+// apply 将参数写入岗位描述，需要时从粘贴的原文中提取字段，并校验必填项
+func (s *JobPostingService) apply(ctx context.Context, job *model.JobPosting, input JobPostingInput) error {
+	if text := strings.TrimSpace(input.Text); text != "" {
+		extracted, err := s.extract(ctx, job.UserID, text)
+		if err != nil {
+			return err
+		}
+		if input.Description == "" {
+			input.Description = text
+		}
+		if input.Title == "" {
+			input.Title = extracted.Title
+		}
+		if input.Company == "" {
+			input.Company = extracted.Company
+		}
+		if input.Level == "" {
+			input.Level = extracted.Level
+		}
+		if len(input.RequiredSkills) == 0 {
+			input.RequiredSkills = extracted.RequiredSkills
+		}
+	}
+
+	if input.Title != "" {
+		job.Title = strings.TrimSpace(input.Title)
+	}
+	if input.Company != "" {
+		job.Company = strings.TrimSpace(input.Company)
+	}
+	if input.Level != "" {
+		job.Level = strings.TrimSpace(input.Level)
+	}
+	if input.Description != "" {
+		job.Description = strings.TrimSpace(input.Description)
+	}
+	if len(input.RequiredSkills) > 0 {
+		job.RequiredSkills = normalizeSkills(input.RequiredSkills)
+	}
+
+	if job.Title == "" {
+		return fmt.Errorf("%w：岗位名称不能为空", ErrInvalidJobPosting)
+	}
+	if job.Description == "" && len(job.RequiredSkills) == 0 {
+		return fmt.Errorf("%w：岗位描述和技能要求不能同时为空", ErrInvalidJobPosting)
+	}
+	return nil
+}
+
+// extract 调用大模型从岗位描述原文中提取结构化字段
+func (s *JobPostingService) extract(ctx context.Context, userID uint, text string) (*jobPostingResult, error) {
+	ctx = withUsageScope(ctx, &usageScope{
+		Operation: model.UsageOpParseJobPosting,
+		UserID:    userID,
+	})
+
+	// 检查配额
+	if err := s.quotaService.Check(userID); err != nil {
+		return nil, err
+	}
+
+	prompt := `请从 <job> 标签内的岗位描述中提取结构化信息。
+
+请以JSON格式返回，格式如下：
+{
+    "title": "岗位名称",
+    "company": "公司名称，未提及时为空",
+    "level": "职级，如 junior、mid、senior、lead，未提及时根据经验年限推断",
+    "required_skills": ["技能1", "技能2"]
+}`
+
+	result := &jobPostingResult{}
+	_, err := llm.ChatStructured(ctx, s.llm, llm.ChatRequest{
+		Messages: untrustedMessages(recruiterSystemPrompt, prompt, untrustedBlock("job", text)),
+	}, result, s.structuredRetries, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解析岗位描述失败: %w", err)
+	}
+	return result, nil
+}
+
+// normalizeSkills 去除空白和重复的技能
+func normalizeSkills(skills []string) model.StringList {
+	seen := make(map[string]bool, len(skills))
+	result := make(model.StringList, 0, len(skills))
+	for _, skill := range skills {
+		skill = strings.TrimSpace(skill)
+		key := strings.ToLower(skill)
+		if skill == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, skill)
+	}
+	return result
+}
+
+// jobPromptSection 生成面试提示词中的目标岗位部分，要求问题聚焦于候选人与岗位要求之间的差距；
+// 岗位内容由用户提供，通过 jobPromptBlock 放在单独一条用户消息的 <job> 标签内
+func jobPromptSection(job *model.JobPosting) string {
+	if job == nil {
+		return ""
+	}
+	return `
+目标岗位在 <job> 标签内。请对照岗位要求分析候选人经历与岗位之间的差距，问题应重点考察：
+1. 岗位要求但简历中没有体现或体现较弱的技能
+2. 候选人相关经验的深度是否达到岗位职级的要求
+3. 候选人已有经验能否迁移到该岗位的核心工作中
+`
+}
+
+// jobPromptBlock 将目标岗位的内容放入 <job> 标签，没有目标岗位时返回空字符串
+func jobPromptBlock(job *model.JobPosting) string {
+	if job == nil {
+		return ""
+	}
+
+	title := job.Title
+	if job.Company != "" {
+		title = fmt.Sprintf("%s（%s）", job.Title, job.Company)
+	}
+	return untrustedBlock("job", fmt.Sprintf(`岗位：%s
+职级：%s
+技能要求：%s
+岗位描述：
+%s`, title, job.Level, strings.Join(job.RequiredSkills, "、"), job.Description))
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"ai-interview/pkg/deepseek"
+	"ai-interview/pkg/llm"
+
+	"github.com/spf13/viper"
+)
+
+// 面试官系统提示词
+const interviewerSystemPrompt = "你是一个专业的面试官，擅长生成面试题、评估答案和提供反馈。"
+
+var (
+	llmProvider     *llm.ResilientProvider
+	llmProviderOnce sync.Once
+)
+
+// newLLMProvider 返回进程内共享的大模型提供方，所有服务共用同一个熔断器
+func newLLMProvider() llm.Provider {
+	llmProviderOnce.Do(func() {
+		llmProvider = llm.NewResilientProvider(createLLMProvider(), llm.ResilienceConfig{
+			MaxRetries:       viper.GetInt("llm.retry.max_retries"),
+			BaseDelay:        viper.GetDuration("llm.retry.base_delay"),
+			MaxDelay:         viper.GetDuration("llm.retry.max_delay"),
+			FailureThreshold: viper.GetInt("llm.circuit_breaker.failure_threshold"),
+			OpenTimeout:      viper.GetDuration("llm.circuit_breaker.open_timeout"),
+		})
+	})
+	return llmProvider
+}
+
+// LLMHealth 返回大模型提供方的健康状态
+func LLMHealth() llm.Health {
+	newLLMProvider()
+	return llmProvider.Health()
+}
+
+// createLLMProvider 根据配置 llm.provider 创建大模型提供方，默认使用DeepSeek
+func createLLMProvider() llm.Provider {
+	name := strings.ToLower(viper.GetString("llm.provider"))
+	switch name {
+	case "", "deepseek":
+		return deepseek.NewClient()
+	case "openai":
+		return llm.NewOpenAIClient(providerConfig(name))
+	case "ollama":
+		return llm.NewOllamaClient(providerConfig(name))
+	default:
+		log.Printf("未知的大模型提供方 %q，使用DeepSeek", name)
+		return deepseek.NewClient()
+	}
+}
+
+// providerConfig 读取 llm.<name> 下的提供方配置
+func providerConfig(name string) llm.Config {
+	key := func(field string) string {
+		return fmt.Sprintf("llm.%s.%s", name, field)
+	}
+	return llm.Config{
+		Name:   name,
+		APIKey: viper.GetString(key("api_key")),
+		APIURL: viper.GetString(key("api_url")),
+		Defaults: llm.Options{
+			Model:       viper.GetString(key("model")),
+			MaxTokens:   viper.GetInt(key("max_tokens")),
+			Temperature: viper.GetFloat64(key("temperature")),
+		},
+		Timeout: viper.GetDuration(key("timeout")),
+	}
+}
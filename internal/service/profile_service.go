@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-interview/internal/model"
+	"ai-interview/internal/repository"
+	"ai-interview/pkg/llm"
+	"ai-interview/pkg/utils"
+
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrInvalidProfile 无效的简历结构化信息
+	ErrInvalidProfile = errors.New("无效的简历信息")
+	// ErrProfileNotFound 简历的结构化信息尚未提取
+	ErrProfileNotFound = errors.New("简历信息尚未提取")
+)
+
+var (
+	// profileDatePattern 常见的年月写法，如 2019.09、2019/9、2019年9月
+	profileDatePattern = regexp.MustCompile(`^(\d{4})\s*(?:[-./年]\s*(\d{1,2})\s*月?)?$`)
+	// profileDateFormat 规范化后的日期格式
+	profileDateFormat = regexp.MustCompile(`^\d{4}(-(0[1-9]|1[0-2]))?$`)
+	// 表示“至今”的写法
+	presentWords = []string{"至今", "今", "现在", "目前", "present", "now", "current", "today"}
+)
+
+// ResumeProfileInput 用户修正简历结构化信息的参数，未提供的字段保持不变，列表整体替换
+type ResumeProfileInput struct {
+	Name            *string                      `json:"name"`
+	Phone           *string                      `json:"phone"`
+	Email           *string                      `json:"email"`
+	Location        *string                      `json:"location"`
+	Headline        *string                      `json:"headline"`
+	Summary         *string                      `json:"summary"`
+	Skills          []string                     `json:"skills"`
+	Educations      []model.ProfileEducation     `json:"educations"`
+	WorkExperiences []model.ProfileWork          `json:"work_experiences"`
+	Projects        []model.ProfileProject       `json:"projects"`
+	Certifications  []model.ProfileCertification `json:"certifications"`
+}
+
+// ResumeProfileService 简历结构化信息服务
+type ResumeProfileService struct {
+	resumeService     *ResumeService
+	profileRepo       *repository.ResumeProfileRepository
+	quotaService      *QuotaService
+	llm               llm.Provider
+	structuredRetries int
+}
+
+// NewResumeProfileService 创建新的简历结构化信息服务
+func NewResumeProfileService() *ResumeProfileService {
+	return &ResumeProfileService{
+		resumeService:     NewResumeService(),
+		profileRepo:       repository.NewResumeProfileRepository(),
+		quotaService:      NewQuotaService(),
+		llm:               newMeteredProvider(NewUsageService()),
+		structuredRetries: viper.GetInt("llm.structured_retries"),
+	}
+}
+
+// GetProfile 获取简历的结构化信息，尚未提取时返回 ErrProfileNotFound
+func (s *ResumeProfileService) GetProfile(ctx context.Context, userID, resumeID uint) (*model.ResumeProfile, error) {
+	if _, err := s.ownedResume(userID, resumeID); err != nil {
+		return nil, err
+	}
+	profile, err := s.profileRepo.GetByResumeID(resumeID)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取简历信息失败: %v", err)
+	}
+	return profile, nil
+}
+
+// Extract 重新提取简历的结构化信息，覆盖已有内容（包括用户的修正）
+func (s *ResumeProfileService) Extract(ctx context.Context, userID, resumeID uint) (*model.ResumeProfile, error) {
+	resume, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.profileRepo.GetByResumeID(resumeID)
+	if gorm.IsRecordNotFoundError(err) {
+		existing = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("获取简历信息失败: %v", err)
+	}
+	return s.extract(ctx, resume, existing)
+}
+
+// UpdateProfile 修正简历的结构化信息，尚未提取时直接按用户填写的内容创建
+func (s *ResumeProfileService) UpdateProfile(ctx context.Context, userID, resumeID uint, input ResumeProfileInput) (*model.ResumeProfile, error) {
+	profile, err := s.GetProfile(ctx, userID, resumeID)
+	if errors.Is(err, ErrProfileNotFound) {
+		profile, err = &model.ResumeProfile{UserID: userID, ResumeID: resumeID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfileInput(profile, input); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	profile.EditedAt = &now
+	if err := s.profileRepo.Save(profile); err != nil {
+		return nil, fmt.Errorf("保存简历信息失败: %v", err)
+	}
+	return profile, nil
+}
+
+// ownedResume 获取简历并校验归属，处理中的简历不能提取信息
+func (s *ResumeProfileService) ownedResume(userID, resumeID uint) (*model.Resume, error) {
+	resume, err := s.resumeService.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResumeReady(resume); err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+// extract 结合规则提取和大模型提取生成结构化信息并保存，existing 不为空时替换其内容
+func (s *ResumeProfileService) extract(ctx context.Context, resume *model.Resume, existing *model.ResumeProfile) (*model.ResumeProfile, error) {
+	ctx = withUsageScope(ctx, &usageScope{
+		Operation: model.UsageOpExtractProfile,
+		UserID:    resume.UserID,
+		ResumeID:  resume.ID,
+	})
+
+	// 检查配额
+	if err := s.quotaService.Check(resume.UserID); err != nil {
+		return nil, err
+	}
+
+	rules := utils.ExtractResumeInfo(resumeDocument(resume))
+	result := &profileResult{}
+	_, err := llm.ChatStructured(ctx, s.llm, llm.ChatRequest{
+		Messages: untrustedMessages(recruiterSystemPrompt, profilePrompt(rules), untrustedBlock("resume", resumePromptText(resume))),
+	}, result, s.structuredRetries, nil)
+	if err != nil {
+		return nil, fmt.Errorf("提取简历信息失败: %w", err)
+	}
+
+	profile := result.toProfile()
+	mergeProfileRules(profile, rules, resume.Content)
+	profile.UserID = resume.UserID
+	profile.ResumeID = resume.ID
+	if existing != nil {
+		profile.Model = existing.Model
+	}
+	if err := s.profileRepo.Save(profile); err != nil {
+		return nil, fmt.Errorf("保存简历信息失败: %v", err)
+	}
+	return profile, nil
+}
+
+// profilePrompt 构建结构化信息提取提示词，附上规则提取的联系方式供模型核对；简历在单独的用户消息中
+func profilePrompt(rules map[string]string) string {
+	var hints strings.Builder
+	for _, key := range []string{"name", "phone", "email"} {
+		if value := rules[key]; value != "" {
+			hints.WriteString(fmt.Sprintf("%s: %s\n", key, value))
+		}
+	}
+	if hints.Len() == 0 {
+		hints.WriteString("无\n")
+	}
+
+	return fmt.Sprintf(`请从 <resume> 标签内的简历中提取结构化信息。
+
+规则识别到的联系方式（仅供核对，可能有误）：
+%s%s
+要求：
+1. 只提取简历中明确出现的信息，没有的字段留空，不要编造
+2. 日期统一为 YYYY-MM 格式，只有年份时为 YYYY，“至今”的结束日期写 present
+3. 工作经历和项目经历按时间倒序排列
+
+请以JSON格式返回，格式如下：
+{
+    "name": "姓名",
+    "phone": "电话",
+    "email": "邮箱",
+    "location": "所在城市",
+    "headline": "求职意向或当前职位",
+    "summary": "个人简介",
+    "skills": ["技能1", "技能2"],
+    "educations": [{"school": "学校", "degree": "学历", "major": "专业", "start_date": "2015-09", "end_date": "2019-06", "description": "描述"}],
+    "work_experiences": [{"company": "公司", "title": "职位", "start_date": "2019-07", "end_date": "present", "description": "工作内容"}],
+    "projects": [{"name": "项目名称", "role": "担任角色", "start_date": "2020-01", "end_date": "2020-12", "technologies": ["技术1"], "description": "项目描述"}],
+    "certifications": [{"name": "证书名称", "issuer": "颁发机构", "date": "2021-05"}]
+}`, hints.String(), languagePromptSection(rules["language"]))
+}
+
+// mergeProfileRules 用规则提取的结果补充和校正模型输出：模型未提取或提取的联系方式不在简历原文中时使用规则结果
+func mergeProfileRules(profile *model.ResumeProfile, rules map[string]string, content string) {
+	if profile.Name == "" {
+		profile.Name = rules["name"]
+	}
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(content)
+	if phone := rules["phone"]; phone != "" &&
+		(profile.Phone == "" || !strings.Contains(compact, strings.NewReplacer(" ", "", "-", "").Replace(profile.Phone))) {
+		profile.Phone = phone
+	}
+	if email := rules["email"]; email != "" &&
+		(profile.Email == "" || !strings.Contains(strings.ToLower(content), strings.ToLower(profile.Email))) {
+		profile.Email = email
+	}
+}
+
+// applyProfileInput 将用户的修正写入结构化信息，并规范化和校验日期
+func applyProfileInput(profile *model.ResumeProfile, input ResumeProfileInput) error {
+	setProfileField(&profile.Name, input.Name)
+	setProfileField(&profile.Phone, input.Phone)
+	setProfileField(&profile.Email, input.Email)
+	setProfileField(&profile.Location, input.Location)
+	setProfileField(&profile.Headline, input.Headline)
+	setProfileField(&profile.Summary, input.Summary)
+	if input.Skills != nil {
+		profile.Skills = normalizeSkills(input.Skills)
+	}
+
+	if input.Educations != nil {
+		for i := range input.Educations {
+			e := &input.Educations[i]
+			if strings.TrimSpace(e.School) == "" {
+				return fmt.Errorf("%w：第%d条教育经历的学校不能为空", ErrInvalidProfile, i+1)
+			}
+			if err := normalizeDates(fmt.Sprintf("第%d条教育经历", i+1), &e.StartDate, &e.EndDate); err != nil {
+				return err
+			}
+		}
+		profile.Educations = input.Educations
+	}
+	if input.WorkExperiences != nil {
+		for i := range input.WorkExperiences {
+			w := &input.WorkExperiences[i]
+			if strings.TrimSpace(w.Company) == "" {
+				return fmt.Errorf("%w：第%d条工作经历的公司不能为空", ErrInvalidProfile, i+1)
+			}
+			if _, current := normalizeProfileDate(w.EndDate); current {
+				w.Current = true
+			}
+			if err := normalizeDates(fmt.Sprintf("第%d条工作经历", i+1), &w.StartDate, &w.EndDate); err != nil {
+				return err
+			}
+		}
+		profile.WorkExperiences = input.WorkExperiences
+	}
+	if input.Projects != nil {
+		for i := range input.Projects {
+			p := &input.Projects[i]
+			if strings.TrimSpace(p.Name) == "" {
+				return fmt.Errorf("%w：第%d个项目的名称不能为空", ErrInvalidProfile, i+1)
+			}
+			if err := normalizeDates(fmt.Sprintf("第%d个项目", i+1), &p.StartDate, &p.EndDate); err != nil {
+				return err
+			}
+			p.Technologies = normalizeSkills(p.Technologies)
+		}
+		profile.Projects = input.Projects
+	}
+	if input.Certifications != nil {
+		for i := range input.Certifications {
+			c := &input.Certifications[i]
+			if strings.TrimSpace(c.Name) == "" {
+				return fmt.Errorf("%w：第%d个证书的名称不能为空", ErrInvalidProfile, i+1)
+			}
+			if err := normalizeDates(fmt.Sprintf("第%d个证书", i+1), &c.Date); err != nil {
+				return err
+			}
+		}
+		profile.Certifications = input.Certifications
+	}
+	return nil
+}
+
+// setProfileField 提供了新值时更新字段
+func setProfileField(field *string, value *string) {
+	if value != nil {
+		*field = strings.TrimSpace(*value)
+	}
+}
+
+// normalizeDates 规范化日期字段，格式无法识别时返回 ErrInvalidProfile
+func normalizeDates(label string, dates ...*string) error {
+	for _, date := range dates {
+		if err := validateProfileDates(label, *date); err != nil {
+			return fmt.Errorf("%w：%v", ErrInvalidProfile, err)
+		}
+		*date, _ = normalizeProfileDate(*date)
+	}
+	return nil
+}
+
+// validateProfileDates 校验日期能否规范化为 YYYY-MM 或 YYYY
+func validateProfileDates(label string, dates ...string) error {
+	for _, date := range dates {
+		normalized, current := normalizeProfileDate(date)
+		if normalized != "" && !current && !profileDateFormat.MatchString(normalized) {
+			return fmt.Errorf("%s的日期 %q 格式应为 YYYY-MM", label, date)
+		}
+	}
+	return nil
+}
+
+// normalizeProfileDate 将常见的年月写法规范化为 YYYY-MM 或 YYYY，表示“至今”时返回空字符串和 true
+func normalizeProfileDate(date string) (string, bool) {
+	date = strings.TrimSpace(date)
+	for _, word := range presentWords {
+		if strings.EqualFold(date, word) {
+			return "", true
+		}
+	}
+	m := profileDatePattern.FindStringSubmatch(date)
+	if m == nil {
+		return date, false
+	}
+	if m[2] == "" {
+		return m[1], false
+	}
+	month, _ := strconv.Atoi(m[2])
+	return fmt.Sprintf("%s-%02d", m[1], month), false
+}
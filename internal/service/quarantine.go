@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ai-interview/pkg/utils"
+
+	"github.com/spf13/viper"
+)
+
+// quarantineRecord 隔离文件的说明，与文件一同保存
+type quarantineRecord struct {
+	UserID        uint      `json:"user_id"`
+	FileName      string    `json:"file_name"`
+	Reason        string    `json:"reason"`
+	QuarantinedAt time.Time `json:"quarantined_at"`
+}
+
+// uploadLimits 读取上传文件的安全检查限制，未配置时使用默认值
+func uploadLimits() utils.FileLimits {
+	limits := utils.FileLimits{
+		MaxZipEntries:       viper.GetInt("upload.max_zip_entries"),
+		MaxUncompressedSize: viper.GetInt64("upload.max_uncompressed_size") * 1024 * 1024, // 转换为字节
+	}
+	if limits.MaxZipEntries <= 0 {
+		limits.MaxZipEntries = 1000
+	}
+	if limits.MaxUncompressedSize <= 0 {
+		limits.MaxUncompressedSize = 100 * 1024 * 1024
+	}
+	return limits
+}
+
+// quarantineFile 将未通过安全检查的文件复制到隔离目录，并保存原因供人工排查；
+// 隔离目录不在文件存储中，无法通过下载链接访问。隔离失败只记录日志，不影响返回给用户的结果
+func quarantineFile(tmpPath string, userID uint, fileName, reason string) {
+	dir := viper.GetString("upload.quarantine_path")
+	if dir == "" {
+		dir = "./quarantine"
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Printf("创建隔离目录失败: %v", err)
+		return
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("%d_%s", userID, fileName))
+	if err := copyFile(tmpPath, name); err != nil {
+		log.Printf("隔离文件 %s 失败: %v", fileName, err)
+		return
+	}
+	record, _ := json.MarshalIndent(quarantineRecord{
+		UserID:        userID,
+		FileName:      fileName,
+		Reason:        reason,
+		QuarantinedAt: time.Now(),
+	}, "", "  ")
+	if err := os.WriteFile(name+".json", record, 0600); err != nil {
+		log.Printf("保存隔离记录失败: %v", err)
+	}
+	log.Printf("用户 %d 上传的文件 %s 已隔离: %s", userID, fileName, reason)
+}
+
+// copyFile 复制文件，临时目录与隔离目录可能不在同一文件系统，不能直接重命名
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// 隔离的文件不需要执行或被其他用户读取
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
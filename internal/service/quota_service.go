@@ -0,0 +1,201 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"ai-interview/internal/model"
+	"ai-interview/internal/repository"
+
+	"github.com/spf13/viper"
+)
+
+// 配额周期
+const (
+	QuotaPeriodDaily   = "daily"
+	QuotaPeriodMonthly = "monthly"
+)
+
+// 配额指标
+const (
+	QuotaMetricRequests = "requests"
+	QuotaMetricTokens   = "tokens"
+)
+
+// QuotaLimits 配额上限，0表示不限
+type QuotaLimits struct {
+	DailyRequests   int64 `json:"daily_requests" mapstructure:"daily_requests"`
+	DailyTokens     int64 `json:"daily_tokens" mapstructure:"daily_tokens"`
+	MonthlyRequests int64 `json:"monthly_requests" mapstructure:"monthly_requests"`
+	MonthlyTokens   int64 `json:"monthly_tokens" mapstructure:"monthly_tokens"`
+}
+
+// QuotaStatus 单项配额的使用情况，Remaining 为-1表示不限
+type QuotaStatus struct {
+	Period    string    `json:"period"`
+	Metric    string    `json:"metric"`
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// QuotaReport 用户配额报告
+type QuotaReport struct {
+	UserID   uint          `json:"user_id"`
+	Role     string        `json:"role"`
+	Override bool          `json:"override"` // 是否使用管理员设置的用户配额
+	Limits   QuotaLimits   `json:"limits"`
+	Usage    []QuotaStatus `json:"usage"`
+}
+
+// QuotaExceededError 配额超限错误
+type QuotaExceededError struct {
+	QuotaStatus
+	Quotas []QuotaStatus `json:"quotas"`
+}
+
+// Error 实现error接口
+func (e *QuotaExceededError) Error() string {
+	period := "今日"
+	if e.Period == QuotaPeriodMonthly {
+		period = "本月"
+	}
+	metric := "请求次数"
+	if e.Metric == QuotaMetricTokens {
+		metric = "token用量"
+	}
+	return fmt.Sprintf("%s%s已达上限(%d)，将于%s重置", period, metric, e.Limit, e.ResetAt.Format("2006-01-02 15:04:05"))
+}
+
+// QuotaService 大模型配额服务
+type QuotaService struct {
+	quotaRepo *repository.QuotaRepository
+	usageRepo *repository.UsageRepository
+}
+
+// NewQuotaService 创建新的配额服务
+func NewQuotaService() *QuotaService {
+	return &QuotaService{
+		quotaRepo: repository.NewQuotaRepository(),
+		usageRepo: repository.NewUsageRepository(),
+	}
+}
+
+// Check 调用大模型前检查用户配额，超限时返回 *QuotaExceededError
+func (s *QuotaService) Check(userID uint) error {
+	if !viper.GetBool("quota.enabled") || userID == 0 {
+		return nil
+	}
+
+	report, err := s.GetReport(userID)
+	if err != nil {
+		return fmt.Errorf("获取配额失败: %v", err)
+	}
+
+	for _, status := range report.Usage {
+		if status.Limit > 0 && status.Remaining <= 0 {
+			return &QuotaExceededError{
+				QuotaStatus: status,
+				Quotas:      report.Usage,
+			}
+		}
+	}
+	return nil
+}
+
+// GetReport 获取用户的配额上限及当前使用情况
+func (s *QuotaService) GetReport(userID uint) (*QuotaReport, error) {
+	role, err := s.quotaRepo.GetUserRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	report := &QuotaReport{
+		UserID: userID,
+		Role:   role,
+		Limits: roleLimits(role),
+	}
+
+	override, err := s.quotaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		report.Override = true
+		report.Limits = QuotaLimits{
+			DailyRequests:   override.DailyRequests,
+			DailyTokens:     override.DailyTokens,
+			MonthlyRequests: override.MonthlyRequests,
+			MonthlyTokens:   override.MonthlyTokens,
+		}
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	daily, err := s.usageRepo.Sum(repository.UsageFilter{UserID: userID, From: dayStart})
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := s.usageRepo.Sum(repository.UsageFilter{UserID: userID, From: monthStart})
+	if err != nil {
+		return nil, err
+	}
+
+	dayReset := dayStart.AddDate(0, 0, 1)
+	monthReset := monthStart.AddDate(0, 1, 0)
+	report.Usage = []QuotaStatus{
+		newQuotaStatus(QuotaPeriodDaily, QuotaMetricRequests, report.Limits.DailyRequests, daily.Requests, dayReset),
+		newQuotaStatus(QuotaPeriodDaily, QuotaMetricTokens, report.Limits.DailyTokens, daily.TotalTokens, dayReset),
+		newQuotaStatus(QuotaPeriodMonthly, QuotaMetricRequests, report.Limits.MonthlyRequests, monthly.Requests, monthReset),
+		newQuotaStatus(QuotaPeriodMonthly, QuotaMetricTokens, report.Limits.MonthlyTokens, monthly.TotalTokens, monthReset),
+	}
+	return report, nil
+}
+
+// SetOverride 为用户设置配额覆盖
+func (s *QuotaService) SetOverride(userID uint, limits QuotaLimits) error {
+	return s.quotaRepo.Save(&model.UserQuota{
+		UserID:          userID,
+		DailyRequests:   limits.DailyRequests,
+		DailyTokens:     limits.DailyTokens,
+		MonthlyRequests: limits.MonthlyRequests,
+		MonthlyTokens:   limits.MonthlyTokens,
+	})
+}
+
+// DeleteOverride 删除用户的配额覆盖，恢复使用角色配额
+func (s *QuotaService) DeleteOverride(userID uint) error {
+	return s.quotaRepo.DeleteByUserID(userID)
+}
+
+// roleLimits 读取角色配额 quota.roles.<role>，未配置的角色使用 quota.roles.user
+func roleLimits(role string) QuotaLimits {
+	if role == "" || !viper.IsSet("quota.roles."+role) {
+		role = "user"
+	}
+	var limits QuotaLimits
+	_ = viper.UnmarshalKey("quota.roles."+role, &limits)
+	return limits
+}
+
+// newQuotaStatus 计算单项配额的剩余量，上限为0时不限
+func newQuotaStatus(period, metric string, limit, used int64, resetAt time.Time) QuotaStatus {
+	status := QuotaStatus{
+		Period:  period,
+		Metric:  metric,
+		Limit:   limit,
+		Used:    used,
+		ResetAt: resetAt,
+	}
+	if limit > 0 {
+		status.Remaining = limit - used
+		if status.Remaining < 0 {
+			status.Remaining = 0
+		}
+	} else {
+		status.Remaining = -1
+	}
+	return status
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"ai-interview/pkg/llm"
+	"ai-interview/pkg/utils"
+
+	"github.com/spf13/viper"
+)
+
+// redactingProvider 发送给大模型前将消息中的个人信息替换为占位符，并在回复中恢复原值
+type redactingProvider struct {
+	provider llm.Provider
+	types    []string
+}
+
+// withRedaction 按配置 llm.redaction 为提供方增加个人信息脱敏，未启用时原样返回
+func withRedaction(provider llm.Provider) llm.Provider {
+	if !viper.GetBool("llm.redaction.enabled") {
+		return provider
+	}
+	return &redactingProvider{
+		provider: provider,
+		types:    viper.GetStringSlice("llm.redaction.types"),
+	}
+}
+
+// Name 返回提供方名称
+func (p *redactingProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Chat 脱敏后发起对话补全，恢复回复中的占位符
+func (p *redactingProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	redactor, req := p.redact(req)
+	resp, err := p.provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Content = redactor.Restore(resp.Content)
+	return resp, nil
+}
+
+// ChatStream 脱敏后发起流式对话补全，推送的片段和最终回复都恢复占位符
+func (p *redactingProvider) ChatStream(ctx context.Context, req llm.ChatRequest, onDelta llm.StreamHandler) (*llm.ChatResponse, error) {
+	redactor, req := p.redact(req)
+	var handler llm.StreamHandler
+	restorer := redactor.NewStreamRestorer()
+	if onDelta != nil {
+		handler = func(delta string) error {
+			if text := restorer.Write(delta); text != "" {
+				return onDelta(text)
+			}
+			return nil
+		}
+	}
+
+	resp, err := llm.Complete(ctx, p.provider, req, handler)
+	if err != nil {
+		return nil, err
+	}
+	if onDelta != nil {
+		if text := restorer.Flush(); text != "" {
+			if err := onDelta(text); err != nil {
+				return nil, err
+			}
+		}
+	}
+	resp.Content = redactor.Restore(resp.Content)
+	return resp, nil
+}
+
+// redact 脱敏请求中的全部消息，返回本次请求的脱敏器用于恢复回复；
+// 消息按 untrustedBlock 的标签拆分，姓名只在标签内用户提供的内容中识别和替换
+func (p *redactingProvider) redact(req llm.ChatRequest) (*utils.Redactor, llm.ChatRequest) {
+	redactor := utils.NewRedactor(p.types)
+	var parts []string
+	var untrusted []bool
+	counts := make([]int, len(req.Messages))
+	for i, msg := range req.Messages {
+		msgParts, msgUntrusted := splitUntrusted(msg.Content)
+		parts = append(parts, msgParts...)
+		untrusted = append(untrusted, msgUntrusted...)
+		counts[i] = len(msgParts)
+	}
+	parts = redactor.RedactAll(parts, untrusted)
+
+	messages := make([]llm.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = llm.Message{Role: msg.Role, Content: strings.Join(parts[:counts[i]], "")}
+		parts = parts[counts[i]:]
+	}
+	req.Messages = messages
+	return redactor, req
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+	"ai-interview/pkg/utils"
+
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/viper"
+)
+
+// 简历处理相关错误
+var (
+	ErrResumeProcessing = errors.New("简历正在处理中，请稍后再试")
+	ErrResumeFailed     = errors.New("简历处理失败，请重新上传")
+)
+
+// 简历处理的阶段
+const (
+	resumeStageParsing    = "parsing"    // 解析原始文件
+	resumeStageExtracting = "extracting" // 提取结构化信息
+)
+
+// statusPollInterval 订阅状态时查询数据库的间隔，用于发现其他实例完成的处理
+const statusPollInterval = 2 * time.Second
+
+// ResumeStatus 简历的处理状态
+type ResumeStatus struct {
+	ResumeID  uint      `json:"resume_id"`
+	Status    string    `json:"status"`          // processing、ready 或 failed
+	Stage     string    `json:"stage,omitempty"` // 当前或最后执行的阶段
+	Attempts  int       `json:"attempts"`        // 已执行次数
+	Error     string    `json:"error,omitempty"` // 最近一次失败的原因
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Finished 处理是否已结束
+func (s *ResumeStatus) Finished() bool {
+	return s.Status != model.ResumeStatusProcessing
+}
+
+// GetStatus 获取简历的处理状态
+func (s *ResumeService) GetStatus(userID, resumeID uint) (*ResumeStatus, error) {
+	resume, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resumeStatus(resume)
+}
+
+// WatchStatus 在简历处理状态变化时调用 onChange，处理结束、ctx 取消或 onChange 返回错误时返回
+func (s *ResumeService) WatchStatus(ctx context.Context, userID, resumeID uint, onChange func(*ResumeStatus) error) error {
+	if _, err := s.ownedResume(userID, resumeID); err != nil {
+		return err
+	}
+	changed, cancel := resumeStatusHub.subscribe(resumeID)
+	defer cancel()
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	var last ResumeStatus
+	for {
+		resume, err := s.resumeRepo.GetByID(resumeID)
+		if err != nil {
+			return fmt.Errorf("获取简历失败: %v", err)
+		}
+		status, err := s.resumeStatus(resume)
+		if err != nil {
+			return err
+		}
+		if *status != last {
+			if err := onChange(status); err != nil {
+				return err
+			}
+			last = *status
+		}
+		if status.Finished() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// resumeStatus 由简历及其最近的处理任务生成处理状态，旧记录没有处理任务
+func (s *ResumeService) resumeStatus(resume *model.Resume) (*ResumeStatus, error) {
+	status := &ResumeStatus{
+		ResumeID:  resume.ID,
+		Status:    resume.Status,
+		UpdatedAt: resume.UpdatedAt,
+	}
+	if status.Status == "" {
+		status.Status = model.ResumeStatusReady
+	}
+
+	task, err := s.taskRepo.GetLatestByResumeID(resume.ID, model.TaskTypeProcessResume)
+	if gorm.IsRecordNotFoundError(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取处理任务失败: %v", err)
+	}
+	status.Stage = task.Stage
+	status.Attempts = task.Attempts
+	status.Error = task.Error
+	if task.UpdatedAt.After(status.UpdatedAt) {
+		status.UpdatedAt = task.UpdatedAt
+	}
+	return status, nil
+}
+
+// processResume 后台处理上传的简历：解析原始文件，按配置提取结构化信息，完成后将简历标记为可用
+func (s *ResumeService) processResume(ctx context.Context, task *model.Task, stage func(name string)) error {
+	resume, err := s.resumeRepo.GetByID(task.ResumeID)
+	if gorm.IsRecordNotFoundError(err) {
+		// 简历已被删除
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("获取简历失败: %v", err)
+	}
+
+	// 粘贴的文本在提交时已解析并检查过重复
+	if resume.Document == nil {
+		stage(resumeStageParsing)
+		if err := s.parseStoredResume(ctx, resume); err != nil {
+			return err
+		}
+		if err := s.linkDuplicate(resume); err != nil {
+			return err
+		}
+	}
+
+	if viper.GetBool("tasks.process_resume.extract_profile") {
+		stage(resumeStageExtracting)
+		profiles := NewResumeProfileService()
+		existing, err := profiles.profileRepo.GetByResumeID(resume.ID)
+		if gorm.IsRecordNotFoundError(err) {
+			existing = nil
+		} else if err != nil {
+			return fmt.Errorf("获取简历信息失败: %v", err)
+		}
+		// 用户已修正过的信息不覆盖
+		if existing == nil || existing.EditedAt == nil {
+			if _, err := profiles.extract(ctx, resume, existing); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.resumeRepo.UpdateFields(resume.ID, map[string]interface{}{"status": model.ResumeStatusReady})
+}
+
+// parseStoredResume 从文件存储读取原始文件并解析，保存解析结果
+func (s *ResumeService) parseStoredResume(ctx context.Context, resume *model.Resume) error {
+	src, err := s.storage.Get(ctx, resumeFileKey(resume))
+	if err != nil {
+		return fmt.Errorf("读取简历文件失败: %w", err)
+	}
+	defer src.Close()
+
+	// 解析器需要读取本地文件
+	tmp, err := os.CreateTemp("", "resume-*"+filepath.Ext(resume.FileName))
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save file: %v", err)
+	}
+
+	parser := utils.NewResumeParser(tmp.Name())
+	doc, err := parser.Parse()
+	if err != nil {
+		return fmt.Errorf("failed to parse resume: %v", err)
+	}
+
+	resume.ContentType = parser.ContentType
+	resume.Content = doc.Text()
+	resume.Document = doc
+	resume.Language = utils.DetectLanguage(resume.Content)
+	resume.Fingerprint = utils.TextFingerprint(resume.Content)
+	resume.SimHash = utils.TextSimHash(resume.Content)
+	return s.resumeRepo.UpdateFields(resume.ID, map[string]interface{}{
+		"content_type": resume.ContentType,
+		"content":      resume.Content,
+		"document":     resume.Document,
+		"language":     resume.Language,
+		"fingerprint":  resume.Fingerprint,
+		"sim_hash":     resume.SimHash,
+	})
+}
+
+// linkDuplicate 解析后发现与已有简历的文本内容相同或相似时记录重复的简历，内容相同的优先；
+// 单独上传的简历改为已有简历的新版本，不再作为另一份简历列出，作为新版本上传的简历保持原有归属
+func (s *ResumeService) linkDuplicate(resume *model.Resume) error {
+	existing, err := s.findLinked(resume)
+	if err != nil || existing == nil {
+		return err
+	}
+
+	if resume.RootID != 0 || existing.LogicalID() == resume.ID {
+		resume.DuplicateOf = existing.ID
+		return s.resumeRepo.UpdateFields(resume.ID, map[string]interface{}{"duplicate_of": existing.ID})
+	}
+
+	// 锁定这份简历，处理期间已有其他版本以它为基础时不再改变归属
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked model.Resume
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Select("id").First(&locked, resume.ID).Error; err != nil {
+			return fmt.Errorf("锁定简历失败: %v", err)
+		}
+		var versions int
+		if err := tx.Model(&model.Resume{}).Where("root_id = ?", resume.ID).Count(&versions).Error; err != nil {
+			return fmt.Errorf("获取简历版本失败: %v", err)
+		}
+
+		fields := map[string]interface{}{"duplicate_of": existing.ID, "updated_at": time.Now()}
+		if versions == 0 {
+			if err := setVersion(tx, resume, existing); err != nil {
+				return err
+			}
+			fields["root_id"] = resume.RootID
+			fields["version"] = resume.Version
+		}
+		return tx.Table(model.Resume{}.TableName()).Where("id = ? AND deleted_at IS NULL", resume.ID).Updates(fields).Error
+	})
+	if err != nil {
+		return err
+	}
+	resume.DuplicateOf = existing.ID
+	return nil
+}
+
+// findLinked 查找与解析后的简历内容相同的其他简历，没有时查找相似的简历
+func (s *ResumeService) findLinked(resume *model.Resume) (*model.Resume, error) {
+	if resume.Fingerprint != "" {
+		existing, err := s.resumeRepo.FindByFingerprint(resume.UserID, resume.Fingerprint, resume.ID)
+		if err == nil {
+			return existing, nil
+		}
+		if !gorm.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("查找重复简历失败: %v", err)
+		}
+	}
+	return s.findSimilar(resume.UserID, resume.SimHash, resume.ID)
+}
+
+// processingFailed 重试次数用尽后将简历标记为处理失败
+func (s *ResumeService) processingFailed(task *model.Task, err error) {
+	if err := s.resumeRepo.UpdateFields(task.ResumeID, map[string]interface{}{"status": model.ResumeStatusFailed}); err != nil {
+		log.Printf("更新简历%d的状态失败: %v", task.ResumeID, err)
+	}
+}
+
+// checkResumeReady 检查简历是否可以用于面试、匹配度分析等，解析完成但后续处理失败的简历仍可使用
+func checkResumeReady(resume *model.Resume) error {
+	switch resume.Status {
+	case model.ResumeStatusProcessing:
+		return ErrResumeProcessing
+	case model.ResumeStatusFailed:
+		if resume.Content == "" {
+			return ErrResumeFailed
+		}
+	}
+	return nil
+}
@@ -1,69 +1,339 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
+	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"ai-interview/internal/database"
 	"ai-interview/internal/model"
 	"ai-interview/internal/repository"
+	"ai-interview/pkg/storage"
 	"ai-interview/pkg/utils"
+
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/viper"
 )
 
+// ErrEmptyResume 简历内容为空
+var ErrEmptyResume = errors.New("resume content is empty")
+
+// ErrResumeNotFound 简历不存在
+var ErrResumeNotFound = errors.New("简历不存在")
+
+// 重复上传的类型
+const (
+	DuplicateExact   = "exact"   // 与已上传的文件完全相同，返回已有的简历
+	DuplicateContent = "content" // 文件不同，但规范化后的文本内容相同，返回已有的简历
+	DuplicateNear    = "near"    // 与已有简历相似（SimHash 距离在阈值内），保存为该简历的新版本
+)
+
+// 粘贴文本的格式及对应的内容类型
+var textFormats = map[string]string{
+	"text":     utils.ContentTypeText,
+	"markdown": utils.ContentTypeMarkdown,
+	"md":       utils.ContentTypeMarkdown,
+	"html":     utils.ContentTypeHTML,
+}
+
+// 保存粘贴文本时使用的扩展名
+var textExtensions = map[string]string{
+	utils.ContentTypeText:     ".txt",
+	utils.ContentTypeMarkdown: ".md",
+	utils.ContentTypeHTML:     ".html",
+}
+
+// ResumeTextInput 粘贴的简历文本
+type ResumeTextInput struct {
+	FileName string `json:"file_name"`
+	Format   string `json:"format"` // text、markdown 或 html，为空时自动识别
+	Text     string `json:"text" binding:"required"`
+}
+
 // ResumeService 简历服务
 type ResumeService struct {
-	resumeRepo *repository.ResumeRepository
+	resumeRepo    *repository.ResumeRepository
+	profileRepo   *repository.ResumeProfileRepository
+	interviewRepo *repository.InterviewRepository
+	taskRepo      *repository.TaskRepository
+	fitRepo       *repository.FitReportRepository
+	storage       storage.Storage
 }
 
 // NewResumeService 创建新的简历服务
 func NewResumeService() *ResumeService {
 	return &ResumeService{
-		resumeRepo: repository.NewResumeRepository(),
+		resumeRepo:    repository.NewResumeRepository(),
+		profileRepo:   repository.NewResumeProfileRepository(),
+		interviewRepo: repository.NewInterviewRepository(),
+		taskRepo:      repository.NewTaskRepository(),
+		fitRepo:       repository.NewFitReportRepository(),
+		storage:       newFileStorage(),
 	}
 }
 
-// UploadResume 上传并解析简历
-func (s *ResumeService) UploadResume(userID uint, file *multipart.FileHeader, uploadDir string) (*model.Resume, error) {
+// UploadResume 上传简历，返回处理中的简历，解析在后台完成；
+// 用户上传过相同的文件时返回已有的简历，第二个返回值为重复的类型，不重复时为空
+func (s *ResumeService) UploadResume(ctx context.Context, userID uint, file *multipart.FileHeader) (*model.Resume, string, error) {
+	return s.uploadResume(ctx, userID, nil, file)
+}
+
+// uploadResume 检查上传的简历文件并保存到文件存储，创建后台处理任务，base 不为空时作为该简历的新版本
+func (s *ResumeService) uploadResume(ctx context.Context, userID uint, base *model.Resume, file *multipart.FileHeader) (*model.Resume, string, error) {
 	// 打开上传的文件
 	src, err := file.Open()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open uploaded file: %v", err)
+		return nil, "", fmt.Errorf("failed to open uploaded file: %v", err)
 	}
 	defer src.Close()
 
-	// 生成文件名
-	filename := fmt.Sprintf("%d_%s", time.Now().Unix(), filepath.Base(file.Filename))
+	// 生成文件名，各版本的文件都保留
+	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), utils.SanitizeFileName(file.Filename, "resume"))
 
-	// 保存文件
-	filePath, err := utils.SaveUploadedFile(src, filename, uploadDir)
+	// 先写入临时目录检查文件内容，保存到文件存储后删除
+	tmpPath, err := utils.SaveUploadedFile(src, filename, os.TempDir())
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	defer os.Remove(tmpPath)
 
-	// 解析简历内容
-	parser := utils.NewResumeParser(filePath)
-	content, err := parser.Parse()
+	// 按文件内容检查类型，未通过检查的文件移入隔离目录，不进入解析器和文件存储
+	contentType, err := utils.ValidateFile(tmpPath, filepath.Ext(filename), uploadLimits())
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse resume: %v", err)
+		if errors.Is(err, utils.ErrFileRejected) {
+			quarantineFile(tmpPath, userID, filename, err.Error())
+		}
+		return nil, "", err
 	}
 
-	// 创建简历记录
+	// 上传过相同的文件时不再保存；文本内容相同的不同文件在解析后识别
+	fileHash, err := utils.HashFile(tmpPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing, duplicate, err := s.findDuplicate(userID, fileHash, ""); err != nil || existing != nil {
+		return existing, duplicate, err
+	}
+
+	tmp, err := os.Open(tmpPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open uploaded file: %v", err)
+	}
+	defer tmp.Close()
+
 	resume := &model.Resume{
-		UserID:   userID,
-		FileName: filename,
-		FilePath: filePath,
-		FileSize: file.Size,
-		Content:  content,
+		UserID:      userID,
+		FileName:    filename,
+		FileSize:    file.Size,
+		FileHash:    fileHash,
+		ContentType: contentType,
+	}
+	if err := s.saveResume(ctx, resume, base, tmp); err != nil {
+		return nil, "", err
+	}
+	return resume, "", nil
+}
+
+// CreateResumeFromText 保存粘贴的简历文本，按格式转换为与上传文件一致的内容；
+// 与已有简历的文本内容相同时返回已有的简历，与已有简历相似时保存为其新版本，第二个返回值为重复的类型，不重复时为空
+func (s *ResumeService) CreateResumeFromText(ctx context.Context, userID uint, input ResumeTextInput) (*model.Resume, string, error) {
+	return s.createResumeFromText(ctx, userID, nil, input)
+}
+
+// createResumeFromText 解析粘贴的简历文本并将原文保存到文件存储，后续处理在后台完成，base 不为空时作为该简历的新版本
+func (s *ResumeService) createResumeFromText(ctx context.Context, userID uint, base *model.Resume, input ResumeTextInput) (*model.Resume, string, error) {
+	contentType := utils.DetectTextFormat(input.Text)
+	if input.Format != "" {
+		var ok bool
+		if contentType, ok = textFormats[strings.ToLower(input.Format)]; !ok {
+			return nil, "", fmt.Errorf("%w: %s", utils.ErrUnsupportedFormat, input.Format)
+		}
+	}
+
+	doc, err := utils.ParseText(input.Text, contentType)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse resume: %w", err)
+	}
+	content := doc.Text()
+	if strings.TrimSpace(content) == "" {
+		return nil, "", ErrEmptyResume
+	}
+
+	// 文本已解析，重复的原文和内容相同的简历都可以直接识别
+	fileHash, fingerprint := utils.HashText(input.Text), utils.TextFingerprint(content)
+	if existing, duplicate, err := s.findDuplicate(userID, fileHash, fingerprint); err != nil || existing != nil {
+		return existing, duplicate, err
+	}
+
+	// 单独提交的简历与已有简历相似时作为其新版本
+	simHash := utils.TextSimHash(content)
+	var similar *model.Resume
+	if base == nil {
+		if similar, err = s.findSimilar(userID, simHash, 0); err != nil {
+			return nil, "", err
+		}
+		base = similar
+	}
+
+	// 保存原文，文件名使用与格式一致的扩展名
+	name := utils.SanitizeFileName(strings.TrimSuffix(input.FileName, filepath.Ext(input.FileName)), "resume")
+	resume := &model.Resume{
+		UserID:      userID,
+		FileName:    fmt.Sprintf("%d_%s%s", time.Now().UnixNano(), name, textExtensions[contentType]),
+		FileSize:    int64(len(input.Text)),
+		FileHash:    fileHash,
+		Fingerprint: fingerprint,
+		SimHash:     simHash,
+		ContentType: contentType,
+		Content:     content,
+		Document:    doc,
+		Language:    utils.DetectLanguage(content),
+	}
+	if similar != nil {
+		resume.DuplicateOf = similar.ID
+	}
+	if err := s.saveResume(ctx, resume, base, strings.NewReader(input.Text)); err != nil {
+		return nil, "", err
+	}
+	if similar != nil {
+		return resume, DuplicateNear, nil
+	}
+	return resume, "", nil
+}
+
+// findDuplicate 按文件哈希和文本指纹查找用户已有的相同简历，没有时返回 nil
+func (s *ResumeService) findDuplicate(userID uint, fileHash, fingerprint string) (*model.Resume, string, error) {
+	existing, err := s.resumeRepo.FindByFileHash(userID, fileHash)
+	if err == nil {
+		return existing, DuplicateExact, nil
+	}
+	if !gorm.IsRecordNotFoundError(err) {
+		return nil, "", fmt.Errorf("查找重复简历失败: %v", err)
+	}
+	if fingerprint == "" {
+		return nil, "", nil
+	}
+
+	existing, err = s.resumeRepo.FindByFingerprint(userID, fingerprint, 0)
+	if err == nil {
+		return existing, DuplicateContent, nil
+	}
+	if !gorm.IsRecordNotFoundError(err) {
+		return nil, "", fmt.Errorf("查找重复简历失败: %v", err)
+	}
+	return nil, "", nil
+}
+
+// findSimilar 查找用户与 SimHash 距离最近且在阈值内的其他简历，没有时返回 nil
+func (s *ResumeService) findSimilar(userID uint, simHash string, excludeID uint) (*model.Resume, error) {
+	if simHash == "" {
+		return nil, nil
+	}
+	candidates, err := s.resumeRepo.ListSimHashes(userID, excludeID)
+	if err != nil {
+		return nil, fmt.Errorf("查找相似简历失败: %v", err)
 	}
 
-	// 保存到数据库
-	if err := database.DB.Create(resume).Error; err != nil {
-		return nil, fmt.Errorf("failed to save resume: %v", err)
+	var similar *model.Resume
+	best := utils.NearDuplicateDistance + 1
+	for i := range candidates {
+		// 候选按ID倒序排列，距离相同时取最新的
+		if d := utils.SimHashDistance(simHash, candidates[i].SimHash); d >= 0 && d < best {
+			similar, best = &candidates[i], d
+		}
+	}
+	return similar, nil
+}
+
+// saveResume 将原始文件保存到文件存储，创建处理中的简历记录及其后台处理任务，创建失败时删除已保存的文件
+func (s *ResumeService) saveResume(ctx context.Context, resume, base *model.Resume, file io.Reader) error {
+	resume.FileKey = fmt.Sprintf("resumes/%d/%s", resume.UserID, resume.FileName)
+	if err := s.storage.Put(ctx, resume.FileKey, file, resume.FileSize, resume.ContentType); err != nil {
+		return fmt.Errorf("failed to store resume file: %w", err)
+	}
+	resume.Status = model.ResumeStatusProcessing
+	err := createWithTask(resume, func(tx *gorm.DB) error {
+		return setVersion(tx, resume, base)
+	}, func() *model.Task {
+		return newTask(model.TaskTypeProcessResume, resume.ID)
+	})
+	if err != nil {
+		s.storage.Delete(ctx, resume.FileKey)
+		if errors.Is(err, ErrResumeNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to save resume: %v", err)
+	}
+	return nil
+}
+
+// DownloadURL 生成简历原始文件的签名下载链接，有效期由 storage.url_expiry 配置
+func (s *ResumeService) DownloadURL(ctx context.Context, userID, resumeID uint) (string, time.Time, error) {
+	resume, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return "", time.Time{}, err
 	}
 
-	return resume, nil
+	expiry := viper.GetDuration("storage.url_expiry")
+	if expiry <= 0 {
+		expiry = 15 * time.Minute
+	}
+	expiresAt := time.Now().Add(expiry)
+	signed, err := s.storage.SignedURL(ctx, resumeFileKey(resume), storage.URLOptions{
+		Expiry:      expiry,
+		FileName:    resume.FileName,
+		ContentType: resume.ContentType,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("生成下载链接失败: %v", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// OpenResumeFile 打开属于该用户的简历原始文件，调用方负责关闭
+func (s *ResumeService) OpenResumeFile(ctx context.Context, userID, resumeID uint) (io.ReadCloser, *model.Resume, error) {
+	resume, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.storage.Get(ctx, resumeFileKey(resume))
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取简历文件失败: %w", err)
+	}
+	return file, resume, nil
+}
+
+// PreviewResume 将属于该用户的简历渲染为HTML片段，处理完成后才能预览
+func (s *ResumeService) PreviewResume(userID, resumeID uint) (*model.Resume, string, error) {
+	resume, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkResumeReady(resume); err != nil {
+		return nil, "", err
+	}
+	return resume, resumeDocument(resume).HTML(), nil
+}
+
+// OpenSignedFile 校验本地存储的签名下载链接并打开文件，使用S3兼容存储时下载链接直接指向对象存储
+func (s *ResumeService) OpenSignedFile(ctx context.Context, key string, query url.Values) (io.ReadCloser, storage.URLOptions, error) {
+	local, ok := s.storage.(*storage.LocalStorage)
+	if !ok {
+		return nil, storage.URLOptions{}, storage.ErrNotFound
+	}
+	opts, err := local.Verify(key, query)
+	if err != nil {
+		return nil, opts, err
+	}
+	file, err := local.Get(ctx, key)
+	return file, opts, err
 }
 
 // CreateResume 创建简历
@@ -71,9 +341,9 @@ func (s *ResumeService) CreateResume(resume *model.Resume) error {
 	return s.resumeRepo.Create(resume)
 }
 
-// GetResume 获取简历
-func (s *ResumeService) GetResume(id uint) (*model.Resume, error) {
-	return s.resumeRepo.GetByID(id)
+// GetResume 获取属于该用户的简历
+func (s *ResumeService) GetResume(userID, id uint) (*model.Resume, error) {
+	return s.ownedResume(userID, id)
 }
 
 // GetUserResumes 获取用户的所有简历
@@ -81,7 +351,86 @@ func (s *ResumeService) GetUserResumes(userID uint) ([]model.Resume, error) {
 	return s.resumeRepo.GetByUserID(userID)
 }
 
-// DeleteResume 删除简历
-func (s *ResumeService) DeleteResume(id uint) error {
-	return s.resumeRepo.Delete(id)
+// DeleteResume 删除属于该用户的简历及其所有版本，同时删除各版本的结构化信息、匹配度报告、
+// 后台任务和原始文件；面试记录和大模型用量保留作为历史
+func (s *ResumeService) DeleteResume(ctx context.Context, userID, id uint) error {
+	resume, err := s.ownedResume(userID, id)
+	if err != nil {
+		return err
+	}
+	versions, err := s.resumeRepo.GetVersions(resume.LogicalID())
+	if err != nil {
+		return fmt.Errorf("获取简历版本失败: %v", err)
+	}
+	ids := make([]uint, len(versions))
+	for i := range versions {
+		ids[i] = versions[i].ID
+		if err := s.profileRepo.DeleteByResumeID(versions[i].ID); err != nil {
+			return fmt.Errorf("删除简历信息失败: %v", err)
+		}
+	}
+	if err := s.fitRepo.DeleteByResumeIDs(ids); err != nil {
+		return fmt.Errorf("删除匹配度报告失败: %v", err)
+	}
+	if err := s.taskRepo.DeleteByResumeIDs(ids); err != nil {
+		return fmt.Errorf("删除后台任务失败: %v", err)
+	}
+	if err := s.resumeRepo.DeleteVersions(resume.LogicalID()); err != nil {
+		return fmt.Errorf("删除简历失败: %v", err)
+	}
+
+	// 记录删除后再删除文件，文件删除失败只记录日志
+	for i := range versions {
+		if err := s.storage.Delete(ctx, resumeFileKey(&versions[i])); err != nil {
+			log.Printf("删除简历%d的文件失败: %v", versions[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// resumeDocument 返回简历的结构化内容，旧记录没有结构化内容时由纯文本构建
+func resumeDocument(resume *model.Resume) *utils.Document {
+	if resume.Document != nil && len(resume.Document.Blocks) > 0 {
+		return resume.Document
+	}
+	return utils.TextDocument(resume.Content)
+}
+
+// resumePromptText 返回提示词中使用的简历内容，有结构化内容时渲染为Markdown以保留标题和表格
+func resumePromptText(resume *model.Resume) string {
+	if resume.Document != nil && len(resume.Document.Blocks) > 0 {
+		return resume.Document.Markdown()
+	}
+	return resume.Content
+}
+
+// resumeLanguage 返回简历语言，旧记录没有保存语言时按内容识别
+func resumeLanguage(resume *model.Resume) string {
+	if resume.Language != "" {
+		return resume.Language
+	}
+	return utils.DetectLanguage(resume.Content)
+}
+
+// languagePromptSection 生成提示词中的语言要求，中文简历不需要额外说明
+func languagePromptSection(language string) string {
+	switch language {
+	case utils.LanguageEnglish:
+		return `
+语言要求：候选人简历为英文，问题、评分标准、评价等文字内容请使用英文，从简历中提取的信息保留原文，不要翻译。
+`
+	case utils.LanguageBilingual:
+		return `
+语言要求：候选人简历为中英双语，文字内容请使用中文，专有名词和技术术语保留英文原文，从简历中提取的信息不要翻译。
+`
+	}
+	return ""
+}
+
+// resumeFileKey 返回简历原始文件在文件存储中的键，旧记录没有保存键时文件位于本地存储根目录下
+func resumeFileKey(resume *model.Resume) string {
+	if resume.FileKey != "" {
+		return resume.FileKey
+	}
+	return resume.FileName
 }
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"time"
+
+	"ai-interview/internal/model"
+	"ai-interview/pkg/utils"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ErrResumeVersionNotFound 简历版本不存在
+var ErrResumeVersionNotFound = errors.New("简历版本不存在")
+
+// ResumeVersionInfo 简历版本的概要
+type ResumeVersionInfo struct {
+	ResumeID  uint      `json:"resume_id"`
+	Version   int       `json:"version"`
+	FileName  string    `json:"file_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VersionScore 一个版本的面试得分
+type VersionScore struct {
+	ResumeVersionInfo
+	Interviews   int      `json:"interviews"`    // 面试次数
+	Answers      int      `json:"answers"`       // 已作答题数
+	AverageScore float64  `json:"average_score"` // 已作答题目的平均得分
+	Change       *float64 `json:"change"`        // 与上一个有作答记录的版本相比平均分的变化，无可比较的版本时为空
+}
+
+// ResumeDiff 两个版本之间的差异及各版本的面试得分走势
+type ResumeDiff struct {
+	From       ResumeVersionInfo   `json:"from"`
+	To         ResumeVersionInfo   `json:"to"`
+	Sections   []utils.SectionDiff `json:"sections"`
+	ScoreTrend []VersionScore      `json:"score_trend"`
+}
+
+// UploadResumeVersion 上传简历的新版本，上传过相同的文件时返回已有的简历及重复的类型
+func (s *ResumeService) UploadResumeVersion(ctx context.Context, userID, resumeID uint, file *multipart.FileHeader) (*model.Resume, string, error) {
+	base, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.uploadResume(ctx, userID, base, file)
+}
+
+// CreateResumeVersionFromText 以粘贴的文本创建简历的新版本，与已有简历的文本内容相同时返回已有的简历及重复的类型
+func (s *ResumeService) CreateResumeVersionFromText(ctx context.Context, userID, resumeID uint, input ResumeTextInput) (*model.Resume, string, error) {
+	base, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.createResumeFromText(ctx, userID, base, input)
+}
+
+// ListVersions 获取简历的所有版本，resumeID 可以是其中任一版本
+func (s *ResumeService) ListVersions(userID, resumeID uint) ([]model.Resume, error) {
+	resume, err := s.ownedResume(userID, resumeID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.resumeRepo.GetVersions(resume.LogicalID())
+	if err != nil {
+		return nil, fmt.Errorf("获取简历版本失败: %v", err)
+	}
+	return versions, nil
+}
+
+// DiffVersions 按段落比较简历的两个版本，from 为0时与 to 的上一个版本比较，to 为0时使用最新版本
+func (s *ResumeService) DiffVersions(userID, resumeID uint, from, to int) (*ResumeDiff, error) {
+	versions, err := s.ListVersions(userID, resumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	toIndex := len(versions) - 1
+	if to != 0 {
+		if toIndex = versionIndex(versions, to); toIndex < 0 {
+			return nil, fmt.Errorf("%w: %d", ErrResumeVersionNotFound, to)
+		}
+	}
+	fromIndex := toIndex - 1
+	if from != 0 {
+		if fromIndex = versionIndex(versions, from); fromIndex < 0 {
+			return nil, fmt.Errorf("%w: %d", ErrResumeVersionNotFound, from)
+		}
+	}
+	if fromIndex < 0 {
+		return nil, fmt.Errorf("%w: 没有可比较的上一个版本", ErrResumeVersionNotFound)
+	}
+
+	trend, err := s.scoreTrend(versions)
+	if err != nil {
+		return nil, err
+	}
+
+	older, newer := &versions[fromIndex], &versions[toIndex]
+	return &ResumeDiff{
+		From:       versionInfo(older),
+		To:         versionInfo(newer),
+		Sections:   utils.DiffDocuments(resumeDocument(older), resumeDocument(newer)),
+		ScoreTrend: trend,
+	}, nil
+}
+
+// scoreTrend 汇总各版本的面试得分，按版本顺序计算平均分的变化
+func (s *ResumeService) scoreTrend(versions []model.Resume) ([]VersionScore, error) {
+	ids := make([]uint, len(versions))
+	for i := range versions {
+		ids[i] = versions[i].ID
+	}
+	scores, err := s.interviewRepo.ScoresByResume(ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取面试得分失败: %v", err)
+	}
+	byResume := make(map[uint]int, len(scores))
+	for i, score := range scores {
+		byResume[score.ResumeID] = i
+	}
+
+	trend := make([]VersionScore, len(versions))
+	var previous *float64
+	for i := range versions {
+		trend[i].ResumeVersionInfo = versionInfo(&versions[i])
+		j, ok := byResume[versions[i].ID]
+		if !ok || scores[j].Answers == 0 {
+			continue
+		}
+		average := math.Round(scores[j].AverageScore*10) / 10
+		trend[i].Interviews = scores[j].Interviews
+		trend[i].Answers = scores[j].Answers
+		trend[i].AverageScore = average
+		if previous != nil {
+			change := math.Round((average-*previous)*10) / 10
+			trend[i].Change = &change
+		}
+		previous = &trend[i].AverageScore
+	}
+	return trend, nil
+}
+
+// setVersion 在事务中将新简历设置为 base 的下一个版本，base 为空时为第一个版本；
+// 先锁定所属简历第一个版本的记录再读取最大版本号，同一份简历的并发新版本在锁上排队，不会分配到相同的版本号
+func setVersion(tx *gorm.DB, resume, base *model.Resume) error {
+	resume.RootID, resume.Version = 0, 1
+	if base == nil {
+		return nil
+	}
+
+	rootID := base.LogicalID()
+	var root model.Resume
+	err := tx.Set("gorm:query_option", "FOR UPDATE").Select("id").First(&root, rootID).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrResumeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("锁定简历失败: %v", err)
+	}
+
+	var latest struct{ Version int }
+	err = tx.Model(&model.Resume{}).
+		Select("COALESCE(MAX(version), 0) AS version").
+		Where("id = ? OR root_id = ?", rootID, rootID).
+		Scan(&latest).Error
+	if err != nil {
+		return fmt.Errorf("获取简历版本失败: %v", err)
+	}
+	resume.RootID, resume.Version = rootID, latest.Version+1
+	return nil
+}
+
+// ownedResume 获取属于该用户的简历
+func (s *ResumeService) ownedResume(userID, resumeID uint) (*model.Resume, error) {
+	resume, err := s.resumeRepo.GetByID(resumeID)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrResumeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取简历失败: %v", err)
+	}
+	if resume.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return resume, nil
+}
+
+// versionIndex 返回版本号在列表中的位置，不存在时返回-1
+func versionIndex(versions []model.Resume, version int) int {
+	for i := range versions {
+		if versions[i].Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// versionInfo 返回简历版本的概要
+func versionInfo(resume *model.Resume) ResumeVersionInfo {
+	return ResumeVersionInfo{
+		ResumeID:  resume.ID,
+		Version:   resume.Version,
+		FileName:  resume.FileName,
+		CreatedAt: resume.CreatedAt,
+	}
+}
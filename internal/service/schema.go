@@ -0,0 +1,321 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ai-interview/internal/model"
+)
+
+// 每次生成的面试题数量
+const defaultQuestionCount = 5
+
+// questionsResult 面试题结构化输出
+type questionsResult struct {
+	count     int
+	Questions []struct {
+		Question           string `json:"question"`
+		EvaluationCriteria string `json:"evaluation_criteria"`
+		Difficulty         string `json:"difficulty"`
+	} `json:"questions"`
+}
+
+// Validate 校验面试题数量及每题内容
+func (r *questionsResult) Validate() error {
+	if len(r.Questions) != r.count {
+		return fmt.Errorf("questions 必须恰好包含%d个问题，实际为%d个", r.count, len(r.Questions))
+	}
+	for i, q := range r.Questions {
+		if strings.TrimSpace(q.Question) == "" {
+			return fmt.Errorf("第%d个问题的 question 不能为空", i+1)
+		}
+		if strings.TrimSpace(q.EvaluationCriteria) == "" {
+			return fmt.Errorf("第%d个问题的 evaluation_criteria 不能为空", i+1)
+		}
+	}
+	return nil
+}
+
+// toQuestions 转换为问题模型
+func (r *questionsResult) toQuestions() []model.Question {
+	questions := make([]model.Question, len(r.Questions))
+	for i, q := range r.Questions {
+		questions[i] = model.Question{
+			Question:           q.Question,
+			EvaluationCriteria: q.EvaluationCriteria,
+			Difficulty:         q.Difficulty,
+		}
+	}
+	return questions
+}
+
+// evaluationResult 答案评估结构化输出
+type evaluationResult struct {
+	Score             int      `json:"score"`
+	Evaluation        string   `json:"evaluation"`
+	Suggestions       []string `json:"suggestions"`
+	InjectionDetected bool     `json:"injection_detected"` // 模型发现回答中有试图影响评分的内容
+}
+
+// Validate 校验分数范围和评价内容
+func (r *evaluationResult) Validate() error {
+	if r.Score < 0 || r.Score > 100 {
+		return fmt.Errorf("score 必须在0-100之间，实际为%d", r.Score)
+	}
+	if strings.TrimSpace(r.Evaluation) == "" {
+		return errors.New("evaluation 不能为空")
+	}
+	return nil
+}
+
+// toEvaluation 转换为评估结果
+func (r *evaluationResult) toEvaluation() *model.Evaluation {
+	return &model.Evaluation{
+		Score:       r.Score,
+		Evaluation:  r.Evaluation,
+		Suggestions: r.Suggestions,
+	}
+}
+
+// feedbackResult 面试反馈结构化输出
+type feedbackResult struct {
+	OverallEvaluation      string   `json:"overall_evaluation"`
+	Strengths              []string `json:"strengths"`
+	Weaknesses             []string `json:"weaknesses"`
+	ImprovementSuggestions []string `json:"improvement_suggestions"`
+	DevelopmentSuggestions []string `json:"development_suggestions"`
+}
+
+// Validate 校验整体评价和各项列表
+func (r *feedbackResult) Validate() error {
+	if strings.TrimSpace(r.OverallEvaluation) == "" {
+		return errors.New("overall_evaluation 不能为空")
+	}
+	if len(r.Strengths) == 0 && len(r.Weaknesses) == 0 {
+		return errors.New("strengths 和 weaknesses 不能同时为空")
+	}
+	return nil
+}
+
+// toFeedback 转换为反馈模型
+func (r *feedbackResult) toFeedback() *model.Feedback {
+	return &model.Feedback{
+		OverallEvaluation:      r.OverallEvaluation,
+		Strengths:              r.Strengths,
+		Weaknesses:             r.Weaknesses,
+		ImprovementSuggestions: r.ImprovementSuggestions,
+		DevelopmentSuggestions: r.DevelopmentSuggestions,
+	}
+}
+
+// nextQuestionResult 会话下一步的结构化输出
+type nextQuestionResult struct {
+	first              bool
+	Action             string `json:"action"`
+	Question           string `json:"question"`
+	EvaluationCriteria string `json:"evaluation_criteria"`
+	Difficulty         string `json:"difficulty"`
+}
+
+// Validate 校验动作和问题内容
+func (r *nextQuestionResult) Validate() error {
+	r.Action = strings.TrimSpace(r.Action)
+	switch r.Action {
+	case nextActionFollowUp, nextActionNewQuestion:
+	case nextActionEnd:
+		if r.first {
+			return errors.New("面试尚未开始，action 不能为 end")
+		}
+		return nil
+	default:
+		return fmt.Errorf("action 必须为 follow_up、new_question 或 end，实际为 %q", r.Action)
+	}
+	if r.first && r.Action == nextActionFollowUp {
+		r.Action = nextActionNewQuestion
+	}
+	if strings.TrimSpace(r.Question) == "" {
+		return errors.New("question 不能为空")
+	}
+	if strings.TrimSpace(r.EvaluationCriteria) == "" {
+		return errors.New("evaluation_criteria 不能为空")
+	}
+	return nil
+}
+
+// jobPostingResult 岗位描述解析结构化输出
+type jobPostingResult struct {
+	Title          string   `json:"title"`
+	Company        string   `json:"company"`
+	Level          string   `json:"level"`
+	RequiredSkills []string `json:"required_skills"`
+}
+
+// Validate 校验岗位名称和技能要求
+func (r *jobPostingResult) Validate() error {
+	if strings.TrimSpace(r.Title) == "" {
+		return errors.New("title 不能为空")
+	}
+	if len(r.RequiredSkills) == 0 {
+		return errors.New("required_skills 不能为空")
+	}
+	return nil
+}
+
+// fitResult 岗位匹配度评估结构化输出
+type fitResult struct {
+	FitScore          int      `json:"fit_score"`
+	SeniorityFit      string   `json:"seniority_fit"`
+	MatchedSkills     []string `json:"matched_skills"`
+	MissingSkills     []string `json:"missing_skills"`
+	Rationale         string   `json:"rationale"`
+	InjectionDetected bool     `json:"injection_detected"` // 模型发现简历中有试图影响评估的内容
+}
+
+// Validate 校验分数范围、职级结论和评估理由
+func (r *fitResult) Validate() error {
+	if r.FitScore < 0 || r.FitScore > 100 {
+		return fmt.Errorf("fit_score 必须在0-100之间，实际为%d", r.FitScore)
+	}
+	r.SeniorityFit = strings.ToLower(strings.TrimSpace(r.SeniorityFit))
+	switch r.SeniorityFit {
+	case model.SeniorityBelow, model.SeniorityMatch, model.SeniorityAbove:
+	default:
+		return fmt.Errorf("seniority_fit 必须为 below、match 或 above，实际为 %q", r.SeniorityFit)
+	}
+	if strings.TrimSpace(r.Rationale) == "" {
+		return errors.New("rationale 不能为空")
+	}
+	return nil
+}
+
+// profileResult 简历结构化信息提取输出
+type profileResult struct {
+	Name       string   `json:"name"`
+	Phone      string   `json:"phone"`
+	Email      string   `json:"email"`
+	Location   string   `json:"location"`
+	Headline   string   `json:"headline"`
+	Summary    string   `json:"summary"`
+	Skills     []string `json:"skills"`
+	Educations []struct {
+		School      string `json:"school"`
+		Degree      string `json:"degree"`
+		Major       string `json:"major"`
+		StartDate   string `json:"start_date"`
+		EndDate     string `json:"end_date"`
+		Description string `json:"description"`
+	} `json:"educations"`
+	WorkExperiences []struct {
+		Company     string `json:"company"`
+		Title       string `json:"title"`
+		StartDate   string `json:"start_date"`
+		EndDate     string `json:"end_date"`
+		Description string `json:"description"`
+	} `json:"work_experiences"`
+	Projects []struct {
+		Name         string   `json:"name"`
+		Role         string   `json:"role"`
+		StartDate    string   `json:"start_date"`
+		EndDate      string   `json:"end_date"`
+		Technologies []string `json:"technologies"`
+		Description  string   `json:"description"`
+	} `json:"projects"`
+	Certifications []struct {
+		Name   string `json:"name"`
+		Issuer string `json:"issuer"`
+		Date   string `json:"date"`
+	} `json:"certifications"`
+}
+
+// Validate 校验各项经历的必填字段和日期格式
+func (r *profileResult) Validate() error {
+	for i, e := range r.Educations {
+		if strings.TrimSpace(e.School) == "" {
+			return fmt.Errorf("第%d条教育经历的 school 不能为空", i+1)
+		}
+		if err := validateProfileDates(fmt.Sprintf("第%d条教育经历", i+1), e.StartDate, e.EndDate); err != nil {
+			return err
+		}
+	}
+	for i, w := range r.WorkExperiences {
+		if strings.TrimSpace(w.Company) == "" {
+			return fmt.Errorf("第%d条工作经历的 company 不能为空", i+1)
+		}
+		if err := validateProfileDates(fmt.Sprintf("第%d条工作经历", i+1), w.StartDate, w.EndDate); err != nil {
+			return err
+		}
+	}
+	for i, p := range r.Projects {
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("第%d个项目的 name 不能为空", i+1)
+		}
+		if err := validateProfileDates(fmt.Sprintf("第%d个项目", i+1), p.StartDate, p.EndDate); err != nil {
+			return err
+		}
+	}
+	for i, c := range r.Certifications {
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("第%d个证书的 name 不能为空", i+1)
+		}
+	}
+	return nil
+}
+
+// toProfile 转换为结构化信息模型，日期统一为 YYYY-MM 格式
+func (r *profileResult) toProfile() *model.ResumeProfile {
+	profile := &model.ResumeProfile{
+		Name:     strings.TrimSpace(r.Name),
+		Phone:    strings.TrimSpace(r.Phone),
+		Email:    strings.TrimSpace(r.Email),
+		Location: strings.TrimSpace(r.Location),
+		Headline: strings.TrimSpace(r.Headline),
+		Summary:  strings.TrimSpace(r.Summary),
+		Skills:   normalizeSkills(r.Skills),
+	}
+	for _, e := range r.Educations {
+		start, _ := normalizeProfileDate(e.StartDate)
+		end, _ := normalizeProfileDate(e.EndDate)
+		profile.Educations = append(profile.Educations, model.ProfileEducation{
+			School:      strings.TrimSpace(e.School),
+			Degree:      strings.TrimSpace(e.Degree),
+			Major:       strings.TrimSpace(e.Major),
+			StartDate:   start,
+			EndDate:     end,
+			Description: strings.TrimSpace(e.Description),
+		})
+	}
+	for _, w := range r.WorkExperiences {
+		start, _ := normalizeProfileDate(w.StartDate)
+		end, current := normalizeProfileDate(w.EndDate)
+		profile.WorkExperiences = append(profile.WorkExperiences, model.ProfileWork{
+			Company:     strings.TrimSpace(w.Company),
+			Title:       strings.TrimSpace(w.Title),
+			StartDate:   start,
+			EndDate:     end,
+			Current:     current,
+			Description: strings.TrimSpace(w.Description),
+		})
+	}
+	for _, p := range r.Projects {
+		start, _ := normalizeProfileDate(p.StartDate)
+		end, _ := normalizeProfileDate(p.EndDate)
+		profile.Projects = append(profile.Projects, model.ProfileProject{
+			Name:         strings.TrimSpace(p.Name),
+			Role:         strings.TrimSpace(p.Role),
+			StartDate:    start,
+			EndDate:      end,
+			Technologies: normalizeSkills(p.Technologies),
+			Description:  strings.TrimSpace(p.Description),
+		})
+	}
+	for _, c := range r.Certifications {
+		date, _ := normalizeProfileDate(c.Date)
+		profile.Certifications = append(profile.Certifications, model.ProfileCertification{
+			Name:   strings.TrimSpace(c.Name),
+			Issuer: strings.TrimSpace(c.Issuer),
+			Date:   date,
+		})
+	}
+	return profile
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"ai-interview/internal/model"
+	"ai-interview/pkg/llm"
+)
+
+func TestQuestionsResultValidate(t *testing.T) {
+	question := `{"question": "介绍一个项目", "evaluation_criteria": "是否说明了个人贡献", "difficulty": "medium"}`
+	questions := func(n int) string {
+		items := make([]string, n)
+		for i := range items {
+			items[i] = question
+		}
+		return `{"questions": [` + strings.Join(items, ",") + `]}`
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "exact count", text: questions(3)},
+		{name: "too few", text: questions(2), wantErr: true},
+		{name: "too many", text: questions(4), wantErr: true},
+		{name: "empty question", text: `{"questions": [` + question + `,` + question + `,{"question": " ", "evaluation_criteria": "x"}]}`, wantErr: true},
+		{name: "empty criteria", text: `{"questions": [` + question + `,` + question + `,{"question": "x", "evaluation_criteria": ""}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := llm.DecodeStructured(tt.text, &questionsResult{count: 3})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeStructured() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluationResultValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  evaluationResult
+		wantErr bool
+	}{
+		{name: "zero", result: evaluationResult{Score: 0, Evaluation: "未作答"}},
+		{name: "hundred", result: evaluationResult{Score: 100, Evaluation: "完整准确"}},
+		{name: "negative", result: evaluationResult{Score: -1, Evaluation: "x"}, wantErr: true},
+		{name: "above hundred", result: evaluationResult{Score: 101, Evaluation: "x"}, wantErr: true},
+		{name: "empty evaluation", result: evaluationResult{Score: 60, Evaluation: " "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.result.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFitResultValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  fitResult
+		wantErr bool
+	}{
+		{name: "valid", result: fitResult{FitScore: 75, SeniorityFit: " Match ", Rationale: "技能基本匹配"}},
+		{name: "score above hundred", result: fitResult{FitScore: 101, SeniorityFit: model.SeniorityMatch, Rationale: "x"}, wantErr: true},
+		{name: "negative score", result: fitResult{FitScore: -5, SeniorityFit: model.SeniorityMatch, Rationale: "x"}, wantErr: true},
+		{name: "unknown seniority", result: fitResult{FitScore: 50, SeniorityFit: "senior", Rationale: "x"}, wantErr: true},
+		{name: "empty rationale", result: fitResult{FitScore: 50, SeniorityFit: model.SeniorityBelow}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.result.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 职级结论统一为小写
+	r := fitResult{FitScore: 75, SeniorityFit: " Match ", Rationale: "x"}
+	if err := r.Validate(); err != nil || r.SeniorityFit != model.SeniorityMatch {
+		t.Errorf("Validate() = %v, seniority_fit = %q, want %q", err, r.SeniorityFit, model.SeniorityMatch)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"ai-interview/pkg/storage"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	fileStorage     storage.Storage
+	fileStorageOnce sync.Once
+)
+
+// newFileStorage 返回进程内共享的文件存储，按配置 storage.driver 选择本地文件系统或S3兼容存储
+func newFileStorage() storage.Storage {
+	fileStorageOnce.Do(func() {
+		fileStorage = createFileStorage()
+	})
+	return fileStorage
+}
+
+// createFileStorage 根据配置创建文件存储，默认使用本地文件系统
+func createFileStorage() storage.Storage {
+	name := strings.ToLower(viper.GetString("storage.driver"))
+	switch name {
+	case "s3":
+		s, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			AccessKey: viper.GetString("storage.s3.access_key"),
+			SecretKey: viper.GetString("storage.s3.secret_key"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			Region:    viper.GetString("storage.s3.region"),
+			UseSSL:    viper.GetBool("storage.s3.use_ssl"),
+		})
+		if err != nil {
+			log.Fatalf("初始化S3存储失败: %v", err)
+		}
+		return s
+	case "", "local":
+	default:
+		log.Printf("未知的文件存储 %q，使用本地文件系统", name)
+	}
+
+	// 根目录未配置时沿用 upload.save_path；签名密钥必须单独配置，不与 jwt.secret 共用
+	root := viper.GetString("storage.local.root")
+	if root == "" {
+		root = viper.GetString("upload.save_path")
+	}
+	signingKey := viper.GetString("storage.local.signing_key")
+	if signingKey == "" {
+		log.Fatal("未配置文件下载链接的签名密钥 storage.local.signing_key")
+	}
+	baseURL := viper.GetString("storage.local.base_url")
+	if baseURL == "" {
+		baseURL = "/api/files"
+	}
+	return storage.NewLocalStorage(storage.LocalConfig{
+		Root:       root,
+		BaseURL:    baseURL,
+		SigningKey: signingKey,
+	})
+}
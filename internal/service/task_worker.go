@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ai-interview/internal/database"
+	"ai-interview/internal/model"
+	"ai-interview/internal/repository"
+
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/viper"
+)
+
+// taskHandler 一类后台任务的处理方法
+type taskHandler struct {
+	// run 执行任务，通过 stage 记录当前阶段；返回错误时按重试策略重新执行
+	run func(ctx context.Context, task *model.Task, stage func(name string)) error
+	// failed 重试次数用尽后调用
+	failed func(task *model.Task, err error)
+}
+
+// taskWakeup 有新任务时唤醒空闲的执行者，不必等到下一次轮询
+var taskWakeup = make(chan struct{}, 1)
+
+// TaskWorker 进程内的后台任务执行者，从数据库领取任务执行；
+// 多个实例可同时运行，任务通过条件更新领取，执行中断的任务在租约到期后被重新领取
+type TaskWorker struct {
+	taskRepo     *repository.TaskRepository
+	handlers     map[string]taskHandler
+	workers      int
+	pollInterval time.Duration
+	lease        time.Duration
+	retryDelay   time.Duration
+}
+
+// NewTaskWorker 创建后台任务执行者，配置见 tasks
+func NewTaskWorker() *TaskWorker {
+	w := &TaskWorker{
+		taskRepo:     repository.NewTaskRepository(),
+		workers:      viper.GetInt("tasks.workers"),
+		pollInterval: viper.GetDuration("tasks.poll_interval"),
+		lease:        viper.GetDuration("tasks.lease"),
+		retryDelay:   viper.GetDuration("tasks.retry_delay"),
+	}
+	if w.pollInterval <= 0 {
+		w.pollInterval = 5 * time.Second
+	}
+	if w.lease <= 0 {
+		w.lease = 10 * time.Minute
+	}
+	if w.retryDelay <= 0 {
+		w.retryDelay = 30 * time.Second
+	}
+
+	resumeService := NewResumeService()
+	w.handlers = map[string]taskHandler{
+		model.TaskTypeProcessResume: {run: resumeService.processResume, failed: resumeService.processingFailed},
+	}
+	return w
+}
+
+// Start 启动 tasks.workers 个后台协程执行任务，为0时不启动，任务由其他实例执行
+func (w *TaskWorker) Start(ctx context.Context) {
+	for i := 0; i < w.workers; i++ {
+		go w.loop(ctx)
+	}
+}
+
+// loop 持续领取并执行任务，没有任务时等待唤醒或下一次轮询
+func (w *TaskWorker) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		task, err := w.claim(time.Now())
+		if err != nil {
+			log.Printf("领取后台任务失败: %v", err)
+		}
+		if task != nil {
+			w.execute(ctx, task)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-taskWakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim 领取一个可执行的任务，没有任务时返回 nil
+func (w *TaskWorker) claim(now time.Time) (*model.Task, error) {
+	tasks, err := w.taskRepo.ListRunnable(now, w.workers+1)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		ok, err := w.taskRepo.Claim(&tasks[i], now, now.Add(w.lease))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &tasks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// execute 执行任务并记录结果，执行时长不超过租约，避免与重新领取的执行者同时执行
+func (w *TaskWorker) execute(ctx context.Context, task *model.Task) {
+	handler, ok := w.handlers[task.Type]
+	if !ok {
+		w.finish(task, fmt.Errorf("未知的任务类型 %s", task.Type), handler)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.lease)
+	defer cancel()
+	stage := func(name string) {
+		task.Stage = name
+		if err := w.taskRepo.UpdateFields(task.ID, map[string]interface{}{"stage": name}); err != nil {
+			log.Printf("更新任务%d的阶段失败: %v", task.ID, err)
+		}
+		resumeStatusHub.publish(task.ResumeID)
+	}
+
+	var err error
+	func() {
+		// 处理方法出现 panic 时按失败处理，不影响执行者继续运行
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("任务执行异常: %v", r)
+			}
+		}()
+		err = handler.run(ctx, task, stage)
+	}()
+	w.finish(task, err, handler)
+}
+
+// finish 记录任务结果：成功时结束任务，失败时按指数退避安排重试，重试次数用尽后结束任务并通知处理方法
+func (w *TaskWorker) finish(task *model.Task, err error, handler taskHandler) {
+	now := time.Now()
+	fields := map[string]interface{}{"locked_until": nil}
+	switch {
+	case err == nil:
+		task.Status = model.TaskStatusSucceeded
+		task.Error = ""
+		fields["finished_at"] = now
+	case task.Attempts < task.MaxAttempts:
+		task.Status = model.TaskStatusPending
+		task.Error = err.Error()
+		fields["run_at"] = now.Add(w.retryDelay << (task.Attempts - 1))
+		log.Printf("任务%d第%d次执行失败，稍后重试: %v", task.ID, task.Attempts, err)
+	default:
+		task.Status = model.TaskStatusFailed
+		task.Error = err.Error()
+		fields["finished_at"] = now
+		log.Printf("任务%d执行失败: %v", task.ID, err)
+		if handler.failed != nil {
+			handler.failed(task, err)
+		}
+	}
+	fields["status"] = task.Status
+	fields["error"] = task.Error
+	if err := w.taskRepo.UpdateFields(task.ID, fields); err != nil {
+		log.Printf("更新任务%d失败: %v", task.ID, err)
+	}
+	resumeStatusHub.publish(task.ResumeID)
+}
+
+// newTask 创建待执行的任务，最多执行次数由 tasks.max_attempts 配置
+func newTask(taskType string, resumeID uint) *model.Task {
+	maxAttempts := viper.GetInt("tasks.max_attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	return &model.Task{
+		Type:        taskType,
+		ResumeID:    resumeID,
+		Status:      model.TaskStatusPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now(),
+	}
+}
+
+// createWithTask 在同一事务中创建记录及其后台任务，task 在记录创建后调用，可使用记录的ID；提交后唤醒执行者。
+// prepare 不为空时在创建记录前于同一事务中调用，用于加锁并设置依赖现有数据的字段
+func createWithTask(record interface{}, prepare func(tx *gorm.DB) error, task func() *model.Task) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if prepare != nil {
+			if err := prepare(tx); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		return tx.Create(task()).Error
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case taskWakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// statusHub 简历处理状态变化的进程内通知，用于向客户端推送状态事件；
+// 其他实例执行的任务不会通知到这里，订阅方需同时定期查询
+type statusHub struct {
+	mu   sync.Mutex
+	subs map[uint]map[chan struct{}]struct{}
+}
+
+// resumeStatusHub 简历处理状态的通知
+var resumeStatusHub = &statusHub{subs: make(map[uint]map[chan struct{}]struct{})}
+
+// subscribe 订阅某份简历的状态变化，返回的函数用于取消订阅
+func (h *statusHub) subscribe(resumeID uint) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[resumeID] == nil {
+		h.subs[resumeID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[resumeID][ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[resumeID], ch)
+		if len(h.subs[resumeID]) == 0 {
+			delete(h.subs, resumeID)
+		}
+	}
+}
+
+// publish 通知某份简历的订阅方状态已变化，订阅方尚未处理上一次通知时合并为一次
+func (h *statusHub) publish(resumeID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[resumeID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
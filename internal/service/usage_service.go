@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"ai-interview/internal/model"
+	"ai-interview/internal/repository"
+	"ai-interview/pkg/llm"
+
+	"github.com/spf13/viper"
+)
+
+// modelPrice 模型价格，单位：每百万token
+type modelPrice struct {
+	Model      string  `mapstructure:"model"`
+	Prompt     float64 `mapstructure:"prompt"`
+	Completion float64 `mapstructure:"completion"`
+}
+
+// usageScope 大模型调用所属的业务对象，通过ctx传递给计量提供方
+type usageScope struct {
+	Operation   string
+	UserID      uint
+	ResumeID    uint
+	InterviewID uint
+	QuestionID  uint
+
+	mu        sync.Mutex
+	recordIDs []uint
+}
+
+type usageScopeKey struct{}
+
+// withUsageScope 在ctx中记录调用所属的业务对象
+func withUsageScope(ctx context.Context, scope *usageScope) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, scope)
+}
+
+// usageScopeFrom 从ctx中获取调用所属的业务对象
+func usageScopeFrom(ctx context.Context) *usageScope {
+	scope, _ := ctx.Value(usageScopeKey{}).(*usageScope)
+	return scope
+}
+
+// UsageService token用量与费用统计服务
+type UsageService struct {
+	usageRepo *repository.UsageRepository
+	prices    map[string]modelPrice
+}
+
+// NewUsageService 创建新的用量服务
+func NewUsageService() *UsageService {
+	var prices []modelPrice
+	if err := viper.UnmarshalKey("llm.pricing", &prices); err != nil {
+		log.Printf("读取模型价格表失败: %v", err)
+	}
+
+	priceTable := make(map[string]modelPrice, len(prices))
+	for _, p := range prices {
+		priceTable[p.Model] = p
+	}
+
+	return &UsageService{
+		usageRepo: repository.NewUsageRepository(),
+		prices:    priceTable,
+	}
+}
+
+// Record 记录一次调用的用量，记录失败只打印日志，不影响业务
+func (s *UsageService) Record(ctx context.Context, provider string, resp *llm.ChatResponse) {
+	usage := &model.LLMUsage{
+		Provider:         provider,
+		ModelName:        resp.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+		Cost:             s.cost(resp.Model, resp.Usage),
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	scope := usageScopeFrom(ctx)
+	if scope != nil {
+		usage.Operation = scope.Operation
+		usage.UserID = scope.UserID
+		usage.ResumeID = scope.ResumeID
+		usage.InterviewID = scope.InterviewID
+		usage.QuestionID = scope.QuestionID
+	}
+
+	if err := s.usageRepo.Create(usage); err != nil {
+		log.Printf("保存用量记录失败: %v", err)
+		return
+	}
+
+	if scope != nil {
+		scope.mu.Lock()
+		scope.recordIDs = append(scope.recordIDs, usage.ID)
+		scope.mu.Unlock()
+	}
+}
+
+// LinkInterview 将本次业务产生的用量记录关联到面试（面试在调用模型之后才创建）
+func (s *UsageService) LinkInterview(scope *usageScope, interviewID uint) {
+	scope.mu.Lock()
+	ids := append([]uint(nil), scope.recordIDs...)
+	scope.mu.Unlock()
+
+	if err := s.usageRepo.LinkInterview(ids, interviewID); err != nil {
+		log.Printf("关联面试用量记录失败: %v", err)
+	}
+}
+
+// GetUserTotals 按用户汇总用量
+func (s *UsageService) GetUserTotals(filter repository.UsageFilter) ([]repository.UsageTotal, error) {
+	return s.usageRepo.SumByUser(filter)
+}
+
+// GetDailyTotals 按天汇总用量
+func (s *UsageService) GetDailyTotals(filter repository.UsageFilter) ([]repository.UsageTotal, error) {
+	return s.usageRepo.SumByDay(filter)
+}
+
+// GetInterviewTotal 汇总单场面试的用量
+func (s *UsageService) GetInterviewTotal(interviewID uint) (*repository.UsageTotal, error) {
+	return s.usageRepo.SumByInterview(interviewID)
+}
+
+// cost 根据价格表计算费用，未配置价格的模型费用为0
+func (s *UsageService) cost(modelName string, usage llm.Usage) float64 {
+	price, ok := s.prices[modelName]
+	if !ok {
+		return 0
+	}
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+}
+
+// meteredProvider 记录每次调用token用量的提供方
+type meteredProvider struct {
+	provider llm.Provider
+	usage    *UsageService
+}
+
+// newMeteredProvider 创建带用量记录的共享大模型提供方，按配置对发送的内容脱敏
+func newMeteredProvider(usage *UsageService) *meteredProvider {
+	return &meteredProvider{
+		provider: withRedaction(newLLMProvider()),
+		usage:    usage,
+	}
+}
+
+// Name 返回提供方名称
+func (p *meteredProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Chat 发起对话补全并记录用量
+func (p *meteredProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	resp, err := p.provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.record(ctx, resp)
+	return resp, nil
+}
+
+// ChatStream 发起流式对话补全并记录用量
+func (p *meteredProvider) ChatStream(ctx context.Context, req llm.ChatRequest, onDelta llm.StreamHandler) (*llm.ChatResponse, error) {
+	resp, err := llm.Complete(ctx, p.provider, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	p.record(ctx, resp)
+	return resp, nil
+}
+
+// record 记录用量，模型未返回名称时使用提供方名称
+func (p *meteredProvider) record(ctx context.Context, resp *llm.ChatResponse) {
+	if resp.Model == "" {
+		resp.Model = p.provider.Name()
+	}
+	p.usage.Record(ctx, p.provider.Name(), resp)
+}
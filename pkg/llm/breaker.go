@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// CircuitBreaker 熔断器：连续失败达到阈值后打开，冷却期内直接失败，冷却结束后放行一次试探请求
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time // 当前时间，测试时可替换
+
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError string
+	probing   bool
+}
+
+// NewCircuitBreaker 创建熔断器，failureThreshold 小于等于0时不熔断
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            CircuitClosed,
+	}
+}
+
+// Allow 判断是否放行请求，熔断中返回 ErrCircuitOpen
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		// 冷却结束，进入半开状态放行一次试探请求
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success 记录成功，关闭熔断器
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+}
+
+// Failure 记录上游故障
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.failureThreshold <= 0 {
+		return
+	}
+	if b.state == CircuitHalfOpen || b.failures >= b.failureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Release 释放未计入成败的试探名额（如调用方取消请求）
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Health 返回熔断器状态快照
+func (b *CircuitBreaker) Health() Health {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	health := Health{
+		Status:              HealthOK,
+		Circuit:             b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != CircuitClosed {
+		health.Status = HealthDegraded
+		retryAt := b.openedAt.Add(b.openTimeout)
+		health.RetryAt = &retryAt
+	}
+	return health
+}
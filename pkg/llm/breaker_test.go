@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestBreaker 创建使用可控时钟的熔断器
+func newTestBreaker(threshold int, timeout time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(threshold, timeout)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+	upstream := errors.New("502 bad gateway")
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() after %d failures = %v, want nil", i, err)
+		}
+		b.Failure(upstream)
+	}
+	if got := b.Health().Circuit; got != CircuitClosed {
+		t.Fatalf("circuit after 2 failures = %s, want %s", got, CircuitClosed)
+	}
+
+	b.Failure(upstream)
+	health := b.Health()
+	if health.Circuit != CircuitOpen || health.Status != HealthDegraded || health.ConsecutiveFailures != 3 {
+		t.Fatalf("Health() = %+v, want open and degraded after 3 failures", health)
+	}
+	if health.LastError != upstream.Error() || health.RetryAt == nil {
+		t.Errorf("Health() = %+v, want last error and retry time", health)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() while open = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+	b.Failure(errors.New("timeout"))
+	b.Success()
+	b.Failure(errors.New("timeout"))
+	if got := b.Health(); got.Circuit != CircuitClosed || got.ConsecutiveFailures != 1 {
+		t.Errorf("Health() = %+v, want closed with 1 failure", got)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe func(b *CircuitBreaker)
+		want  string
+	}{
+		{name: "probe succeeds", probe: func(b *CircuitBreaker) { b.Success() }, want: CircuitClosed},
+		{name: "probe fails", probe: func(b *CircuitBreaker) { b.Failure(errors.New("500")) }, want: CircuitOpen},
+		{name: "probe released", probe: func(b *CircuitBreaker) { b.Release() }, want: CircuitHalfOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, now := newTestBreaker(1, time.Minute)
+			b.Failure(errors.New("500"))
+
+			*now = now.Add(59 * time.Second)
+			if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("Allow() before timeout = %v, want ErrCircuitOpen", err)
+			}
+
+			// 冷却结束后只放行一次试探请求
+			*now = now.Add(time.Second)
+			if err := b.Allow(); err != nil {
+				t.Fatalf("Allow() after timeout = %v, want nil", err)
+			}
+			if got := b.Health().Circuit; got != CircuitHalfOpen {
+				t.Fatalf("circuit after timeout = %s, want %s", got, CircuitHalfOpen)
+			}
+			if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("second Allow() while probing = %v, want ErrCircuitOpen", err)
+			}
+
+			tt.probe(b)
+			if got := b.Health().Circuit; got != tt.want {
+				t.Errorf("circuit after probe = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerReleasedProbeAllowsNext(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.Failure(errors.New("500"))
+	*now = now.Add(time.Minute)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after timeout = %v, want nil", err)
+	}
+	b.Release()
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() after released probe = %v, want nil", err)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b, _ := newTestBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.Failure(errors.New("500"))
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() with threshold 0 = %v, want nil", err)
+	}
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器打开，上游暂不可用
+var ErrCircuitOpen = errors.New("大模型服务暂不可用（熔断中）")
+
+// APIError 上游接口返回的非200错误
+type APIError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter 上游通过 Retry-After 头要求的等待时间
+	RetryAfter time.Duration
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API请求失败(%d): %s", e.StatusCode, e.Body)
+}
+
+// newAPIError 根据HTTP响应创建APIError
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// isRetryable 判断错误是否可以重试：限流、5xx和网络错误可重试，其余4xx不重试
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// isUpstreamFailure 判断错误是否说明上游故障，计入熔断统计；限流和请求错误不计入
+func isUpstreamFailure(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
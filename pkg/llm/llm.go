@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+)
+
+// 消息角色
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message 对话消息
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Options 生成参数
+type Options struct {
+	Model       string  `json:"model,omitempty"`
+	MaxTokens   int     `json:"max_tokens,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+}
+
+// ChatRequest 对话请求
+type ChatRequest struct {
+	Messages []Message
+	Options  Options
+}
+
+// Usage token用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponse 对话响应
+type ChatResponse struct {
+	Model   string
+	Content string
+	Usage   Usage
+}
+
+// Provider 大模型提供方
+type Provider interface {
+	// Name 返回提供方名称
+	Name() string
+	// Chat 发起一次对话补全
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+}
+
+// StreamHandler 流式输出回调，每收到一段增量文本调用一次
+type StreamHandler func(delta string) error
+
+// Streamer 支持流式输出的提供方
+type Streamer interface {
+	// ChatStream 发起流式对话补全，返回拼接后的完整响应
+	ChatStream(ctx context.Context, req ChatRequest, onDelta StreamHandler) (*ChatResponse, error)
+}
+
+// mergeOptions 合并默认参数和请求参数，请求参数优先
+func mergeOptions(defaults, opts Options) Options {
+	if opts.Model == "" {
+		opts.Model = defaults.Model
+	}
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = defaults.MaxTokens
+	}
+	if opts.Temperature == 0 {
+		opts.Temperature = defaults.Temperature
+	}
+	return opts
+}
@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OllamaClient Ollama及本地模型客户端（/api/chat）
+type OllamaClient struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// ollamaRequest Ollama请求
+type ollamaRequest struct {
+	Model    string                 `json:"model"`
+	Messages []Message              `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// ollamaResponse Ollama响应，流式模式下每行一个
+type ollamaResponse struct {
+	Model   string  `json:"model"`
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+	// Ollama 使用独立字段返回token计数
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// usage 转换token用量
+func (r *ollamaResponse) usage() Usage {
+	return Usage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+// NewOllamaClient 创建Ollama客户端
+func NewOllamaClient(config Config) *OllamaClient {
+	if config.Name == "" {
+		config.Name = "ollama"
+	}
+	if config.Timeout == 0 {
+		// 本地模型推理较慢，默认超时更长
+		config.Timeout = 120 * time.Second
+	}
+	return &OllamaClient{
+		config: config,
+		// 超时由每次调用的ctx控制，不设置整体超时
+		httpClient: &http.Client{},
+	}
+}
+
+// Name 返回提供方名称
+func (c *OllamaClient) Name() string {
+	return c.config.Name
+}
+
+// Chat 发起一次对话补全
+func (c *OllamaClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// 单次调用的超时
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	httpReq, err := c.newRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	// 检查响应状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, respBody)
+	}
+
+	// 解析响应
+	var chatResp ollamaResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	return &ChatResponse{
+		Model:   chatResp.Model,
+		Content: chatResp.Message.Content,
+		Usage:   chatResp.usage(),
+	}, nil
+}
+
+// ChatStream 发起流式对话补全，Ollama以换行分隔的JSON返回分片
+func (c *OllamaClient) ChatStream(ctx context.Context, req ChatRequest, onDelta StreamHandler) (*ChatResponse, error) {
+	httpReq, err := c.newRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 检查响应状态码
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, respBody)
+	}
+
+	result := &ChatResponse{}
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("解析流式响应失败: %v", err)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if delta := chunk.Message.Content; delta != "" {
+			content.WriteString(delta)
+			if onDelta != nil {
+				if err := onDelta(delta); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if chunk.Done {
+			result.Usage = chunk.usage()
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取流式响应失败: %w", err)
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
+// newRequest 构建 /api/chat HTTP请求
+func (c *OllamaClient) newRequest(ctx context.Context, req ChatRequest, stream bool) (*http.Request, error) {
+	opts := mergeOptions(c.config.Defaults, req.Options)
+
+	// 构建请求
+	ollamaReq := ollamaRequest{
+		Model:    opts.Model,
+		Messages: req.Messages,
+		Stream:   stream,
+		Options:  map[string]interface{}{},
+	}
+	if opts.MaxTokens > 0 {
+		ollamaReq.Options["num_predict"] = opts.MaxTokens
+	}
+	if opts.Temperature > 0 {
+		ollamaReq.Options["temperature"] = opts.Temperature
+	}
+
+	// 序列化请求
+	reqBody, err := json.Marshal(ollamaReq)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	// 创建HTTP请求
+	url := strings.TrimRight(c.config.APIURL, "/") + "/api/chat"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+	return httpReq, nil
+}
@@ -0,0 +1,243 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Config 提供方配置
+type Config struct {
+	Name     string
+	APIKey   string
+	APIURL   string
+	Defaults Options
+	// Timeout 单次非流式调用的超时，流式调用由调用方ctx控制
+	Timeout time.Duration
+}
+
+// OpenAIClient OpenAI兼容接口客户端（/chat/completions）
+type OpenAIClient struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// openAIRequest OpenAI兼容请求
+type openAIRequest struct {
+	Model         string         `json:"model"`
+	Messages      []Message      `json:"messages"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Temperature   float64        `json:"temperature,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// streamOptions 流式请求选项
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIResponse OpenAI兼容响应
+type openAIResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage Usage `json:"usage"`
+}
+
+// openAIChunk OpenAI兼容流式响应分片
+type openAIChunk struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+}
+
+// NewOpenAIClient 创建OpenAI兼容客户端
+func NewOpenAIClient(config Config) *OpenAIClient {
+	if config.Name == "" {
+		config.Name = "openai"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &OpenAIClient{
+		config: config,
+		// 超时由每次调用的ctx控制，不设置整体超时
+		httpClient: &http.Client{},
+	}
+}
+
+// Name 返回提供方名称
+func (c *OpenAIClient) Name() string {
+	return c.config.Name
+}
+
+// Chat 发起一次对话补全
+func (c *OpenAIClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// 单次调用的超时
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	httpReq, err := c.newRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	// 检查响应状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, respBody)
+	}
+
+	// 解析响应
+	var chatResp openAIResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	// 检查响应内容
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("API返回空响应")
+	}
+
+	return &ChatResponse{
+		Model:   chatResp.Model,
+		Content: chatResp.Choices[0].Message.Content,
+		Usage:   chatResp.Usage,
+	}, nil
+}
+
+// ChatStream 发起流式对话补全（stream: true），逐段回调增量文本
+func (c *OpenAIClient) ChatStream(ctx context.Context, req ChatRequest, onDelta StreamHandler) (*ChatResponse, error) {
+	httpReq, err := c.newRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// 发送请求
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 检查响应状态码
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, respBody)
+	}
+
+	// 逐行解析SSE数据
+	result := &ChatResponse{}
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			// 空行、注释（keep-alive）等忽略
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("解析流式响应失败: %v", err)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				if err := onDelta(choice.Delta.Content); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取流式响应失败: %w", err)
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("API返回空响应")
+	}
+	result.Content = content.String()
+	return result, nil
+}
+
+// newRequest 构建 /chat/completions HTTP请求
+func (c *OpenAIClient) newRequest(ctx context.Context, req ChatRequest, stream bool) (*http.Request, error) {
+	opts := mergeOptions(c.config.Defaults, req.Options)
+
+	body := openAIRequest{
+		Model:       opts.Model,
+		Messages:    req.Messages,
+		MaxTokens:   opts.MaxTokens,
+		Temperature: opts.Temperature,
+	}
+	if stream {
+		body.Stream = true
+		// 在最后一个分片中返回token用量
+		body.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+
+	// 序列化请求
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	// 创建HTTP请求
+	url := strings.TrimRight(c.config.APIURL, "/") + "/chat/completions"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+
+	// 设置请求头
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+	return httpReq, nil
+}
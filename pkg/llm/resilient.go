@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// 健康状态
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// Health 提供方健康状态
+type Health struct {
+	Provider            string     `json:"provider"`
+	Status              string     `json:"status"`
+	Circuit             string     `json:"circuit"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// ResilienceConfig 重试与熔断配置
+type ResilienceConfig struct {
+	// MaxRetries 失败后的最大重试次数
+	MaxRetries int
+	// BaseDelay 指数退避的初始等待时间
+	BaseDelay time.Duration
+	// MaxDelay 单次等待上限，Retry-After 超过该值时不再重试
+	MaxDelay time.Duration
+	// FailureThreshold 连续失败多少次后熔断
+	FailureThreshold int
+	// OpenTimeout 熔断持续时间
+	OpenTimeout time.Duration
+}
+
+// ResilientProvider 为提供方增加重试、指数退避和熔断
+type ResilientProvider struct {
+	provider Provider
+	config   ResilienceConfig
+	breaker  *CircuitBreaker
+	wait     func(ctx context.Context, d time.Duration) error // 重试前等待，测试时可替换
+}
+
+// NewResilientProvider 包装提供方
+func NewResilientProvider(provider Provider, config ResilienceConfig) *ResilientProvider {
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 10 * time.Second
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	return &ResilientProvider{
+		provider: provider,
+		config:   config,
+		breaker:  NewCircuitBreaker(config.FailureThreshold, config.OpenTimeout),
+		wait:     wait,
+	}
+}
+
+// Name 返回提供方名称
+func (p *ResilientProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Chat 发起对话补全，失败时按策略重试
+func (p *ResilientProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var resp *ChatResponse
+	err := p.do(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		resp, err = p.provider.Chat(ctx, req)
+		return true, err
+	})
+	return resp, err
+}
+
+// ChatStream 发起流式对话补全，已经推送过增量文本后不再重试，避免客户端收到重复内容
+func (p *ResilientProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta StreamHandler) (*ChatResponse, error) {
+	var resp *ChatResponse
+	err := p.do(ctx, func(ctx context.Context) (bool, error) {
+		started := false
+		handler := func(delta string) error {
+			started = true
+			if onDelta != nil {
+				return onDelta(delta)
+			}
+			return nil
+		}
+		var err error
+		resp, err = Complete(ctx, p.provider, req, handler)
+		return !started, err
+	})
+	return resp, err
+}
+
+// Health 返回健康状态
+func (p *ResilientProvider) Health() Health {
+	health := p.breaker.Health()
+	health.Provider = p.provider.Name()
+	return health
+}
+
+// do 执行调用，call 返回本次失败是否允许重试
+func (p *ResilientProvider) do(ctx context.Context, call func(ctx context.Context) (bool, error)) error {
+	for attempt := 0; ; attempt++ {
+		if err := p.breaker.Allow(); err != nil {
+			return err
+		}
+
+		canRetry, err := call(ctx)
+		if err == nil {
+			p.breaker.Success()
+			return nil
+		}
+
+		// 调用方取消或超时，不计入上游故障
+		if ctx.Err() != nil {
+			p.breaker.Release()
+			return err
+		}
+		if isUpstreamFailure(err) {
+			p.breaker.Failure(err)
+		} else {
+			p.breaker.Release()
+		}
+
+		if !canRetry || !isRetryable(err) || attempt >= p.config.MaxRetries {
+			return err
+		}
+
+		delay, ok := p.backoff(attempt, err)
+		if !ok {
+			return err
+		}
+		if err := p.wait(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// wait 等待 d 或直到 ctx 结束
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff 计算第 attempt 次失败后的等待时间：带抖动的指数退避，上游返回 Retry-After 时以其为准
+func (p *ResilientProvider) backoff(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if apiErr.RetryAfter > p.config.MaxDelay {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+
+	delay := p.config.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > p.config.MaxDelay {
+		delay = p.config.MaxDelay
+	}
+	// 在 [delay/2, delay) 范围内随机，避免多个请求同时重试
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
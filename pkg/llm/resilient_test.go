@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// scriptedProvider 按顺序返回预设的错误，用完后返回成功
+type scriptedProvider struct {
+	errs  []error
+	calls int
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return nil, p.errs[p.calls-1]
+	}
+	return &ChatResponse{Content: "ok"}, nil
+}
+
+// newTestResilientProvider 创建记录等待时间而不实际等待的提供方
+func newTestResilientProvider(provider Provider, config ResilienceConfig) (*ResilientProvider, *[]time.Duration) {
+	var waits []time.Duration
+	p := NewResilientProvider(provider, config)
+	p.wait = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return p, &waits
+}
+
+func TestResilientProviderRetries(t *testing.T) {
+	rateLimited := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   bool
+		wantCalls int
+		wantWaits []time.Duration // 为空时只检查次数
+		waits     int
+	}{
+		{name: "success", wantCalls: 1},
+		{
+			name:      "429 with Retry-After",
+			errs:      []error{rateLimited},
+			wantCalls: 2,
+			wantWaits: []time.Duration{2 * time.Second},
+			waits:     1,
+		},
+		{
+			name:      "Retry-After above max delay",
+			errs:      []error{&APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{name: "5xx retried", errs: []error{&APIError{StatusCode: 503}, &APIError{StatusCode: 502}}, wantCalls: 3, waits: 2},
+		{name: "network error retried", errs: []error{errors.New("connection reset")}, wantCalls: 2, waits: 1},
+		{name: "400 not retried", errs: []error{&APIError{StatusCode: http.StatusBadRequest}}, wantErr: true, wantCalls: 1},
+		{name: "401 not retried", errs: []error{&APIError{StatusCode: http.StatusUnauthorized}}, wantErr: true, wantCalls: 1},
+		{
+			name:      "retries exhausted",
+			errs:      []error{&APIError{StatusCode: 500}, &APIError{StatusCode: 500}, &APIError{StatusCode: 500}},
+			wantErr:   true,
+			wantCalls: 3,
+			waits:     2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &scriptedProvider{errs: tt.errs}
+			p, waits := newTestResilientProvider(upstream, ResilienceConfig{
+				MaxRetries: 2,
+				BaseDelay:  100 * time.Millisecond,
+				MaxDelay:   10 * time.Second,
+			})
+
+			_, err := p.Chat(context.Background(), ChatRequest{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Chat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if upstream.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", upstream.calls, tt.wantCalls)
+			}
+			if len(*waits) != tt.waits {
+				t.Errorf("waits = %v, want %d waits", *waits, tt.waits)
+			}
+			for i, want := range tt.wantWaits {
+				if i < len(*waits) && (*waits)[i] != want {
+					t.Errorf("wait %d = %v, want %v", i, (*waits)[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestResilientProviderBackoff(t *testing.T) {
+	p, _ := newTestResilientProvider(&scriptedProvider{}, ResilienceConfig{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	})
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 0, max: 100 * time.Millisecond},
+		{attempt: 1, max: 200 * time.Millisecond},
+		{attempt: 2, max: 400 * time.Millisecond},
+		{attempt: 10, max: time.Second},
+	}
+	for _, tt := range tests {
+		delay, ok := p.backoff(tt.attempt, errors.New("timeout"))
+		if !ok || delay < tt.max/2 || delay > tt.max {
+			t.Errorf("backoff(%d) = %v, %v, want between %v and %v", tt.attempt, delay, ok, tt.max/2, tt.max)
+		}
+	}
+}
+
+func TestResilientProviderCircuit(t *testing.T) {
+	upstream := &scriptedProvider{errs: []error{
+		&APIError{StatusCode: 500},
+		&APIError{StatusCode: 500},
+		&APIError{StatusCode: http.StatusTooManyRequests},
+	}}
+	p, _ := newTestResilientProvider(upstream, ResilienceConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.breaker.now = func() time.Time { return now }
+
+	// 连续两次5xx后熔断，不再请求上游
+	for i := 0; i < 2; i++ {
+		if _, err := p.Chat(context.Background(), ChatRequest{}); err == nil {
+			t.Fatalf("Chat() %d error = nil, want upstream error", i)
+		}
+	}
+	if _, err := p.Chat(context.Background(), ChatRequest{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Chat() while open = %v, want ErrCircuitOpen", err)
+	}
+	if upstream.calls != 2 {
+		t.Fatalf("calls while open = %d, want 2", upstream.calls)
+	}
+
+	// 冷却后试探请求遇到限流，不计入上游故障，熔断器保持半开并释放试探名额
+	now = now.Add(time.Minute)
+	if _, err := p.Chat(context.Background(), ChatRequest{}); err == nil {
+		t.Fatal("Chat() probe error = nil, want 429")
+	}
+	if got := p.Health().Circuit; got != CircuitHalfOpen {
+		t.Fatalf("circuit after rate-limited probe = %s, want %s", got, CircuitHalfOpen)
+	}
+
+	// 下一次试探成功后关闭
+	if _, err := p.Chat(context.Background(), ChatRequest{}); err != nil {
+		t.Fatalf("Chat() probe = %v, want nil", err)
+	}
+	if health := p.Health(); health.Circuit != CircuitClosed || health.Status != HealthOK || health.Provider != "scripted" {
+		t.Errorf("Health() = %+v, want closed and ok", health)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "5", want: 5 * time.Second},
+		{value: "-1", want: 0},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
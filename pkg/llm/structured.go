@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// 匹配Markdown代码块 ```json ... ```
+var fencePattern = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*\\n?(.*?)```")
+
+// Validator 可校验的结构化输出，解析成功后调用 Validate 校验字段约束
+type Validator interface {
+	Validate() error
+}
+
+// Complete 调用大模型，onDelta 不为空且提供方支持时使用流式输出
+func Complete(ctx context.Context, p Provider, req ChatRequest, onDelta StreamHandler) (*ChatResponse, error) {
+	if streamer, ok := p.(Streamer); ok && onDelta != nil {
+		return streamer.ChatStream(ctx, req, onDelta)
+	}
+
+	resp, err := p.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// 不支持流式的提供方一次性推送完整结果
+	if onDelta != nil {
+		if err := onDelta(resp.Content); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// ChatStructured 调用大模型并将回复解析到 out，解析或校验失败时把错误信息反馈给模型重新生成，最多重试 maxRetries 次；
+// 每次都解析到 out 初始值的副本（保留调用方预设的校验参数），校验通过后才写入 out，失败的回复不会残留字段
+func ChatStructured(ctx context.Context, p Provider, req ChatRequest, out interface{}, maxRetries int, onDelta StreamHandler) (*ChatResponse, error) {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return nil, errors.New("out 必须是非空指针")
+	}
+	initial := reflect.ValueOf(target.Elem().Interface())
+
+	messages := append([]Message(nil), req.Messages...)
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		// 只有第一次请求流式推送，重试的内容不再推给客户端
+		handler := onDelta
+		if attempt > 0 {
+			handler = nil
+		}
+
+		resp, err := Complete(ctx, p, ChatRequest{Messages: messages, Options: req.Options}, handler)
+		if err != nil {
+			return nil, err
+		}
+
+		fresh := reflect.New(target.Elem().Type())
+		fresh.Elem().Set(initial)
+		if lastErr = DecodeStructured(resp.Content, fresh.Interface()); lastErr == nil {
+			target.Elem().Set(fresh.Elem())
+			return resp, nil
+		}
+
+		// 携带校验错误重新请求
+		messages = append(messages,
+			Message{Role: RoleAssistant, Content: resp.Content},
+			Message{Role: RoleUser, Content: fmt.Sprintf("你上一次的回复未通过校验：%v。请修正后重新输出，只返回符合要求的JSON，不要包含任何其他内容。", lastErr)},
+		)
+	}
+
+	return nil, fmt.Errorf("结构化输出校验失败（已重试%d次）: %v", maxRetries, lastErr)
+}
+
+// DecodeStructured 从模型回复中提取JSON并解析到 out，out 实现 Validator 时同时校验
+func DecodeStructured(text string, out interface{}) error {
+	payload, err := ExtractJSON(text)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(payload), out); err != nil {
+		return fmt.Errorf("解析JSON失败: %v", err)
+	}
+	if v, ok := out.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// ExtractJSON 从模型回复中提取JSON：去除代码块标记和前后说明文字，并修复多余的尾随逗号；
+// 说明文字中带括号（如“[注意]”）时跳过无法解析的片段，都无法解析时返回第一个完整的片段，由调用方报告解析错误
+func ExtractJSON(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if m := fencePattern.FindStringSubmatch(text); m != nil {
+		text = strings.TrimSpace(m[1])
+	}
+
+	first := ""
+	var lastErr error
+	for offset := 0; offset < len(text); {
+		i := strings.IndexAny(text[offset:], "{[")
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		offset = start + 1
+
+		end, err := matchBracket(text, start)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		candidate := removeTrailingCommas(text[start : end+1])
+		if json.Valid([]byte(candidate)) {
+			return candidate, nil
+		}
+		if first == "" {
+			first = candidate
+		}
+	}
+
+	switch {
+	case first != "":
+		return first, nil
+	case lastErr != nil:
+		return "", lastErr
+	default:
+		return "", errors.New("回复中未找到JSON")
+	}
+}
+
+// matchBracket 返回与 start 处括号配对的结束位置，忽略字符串中的括号
+func matchBracket(text string, start int) (int, error) {
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		ch := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.New("JSON不完整，缺少结束括号")
+}
+
+// removeTrailingCommas 删除对象或数组结尾处多余的逗号
+func removeTrailingCommas(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			b.WriteByte(ch)
+			continue
+		}
+
+		if ch == '"' {
+			inString = true
+		}
+		if ch == ',' {
+			next := strings.TrimLeft(s[i+1:], " \t\r\n")
+			if strings.HasPrefix(next, "}") || strings.HasPrefix(next, "]") {
+				continue
+			}
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
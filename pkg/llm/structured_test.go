@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain object", text: `{"score": 80}`, want: `{"score": 80}`},
+		{name: "fenced", text: "```json\n{\"score\": 80}\n```", want: `{"score": 80}`},
+		{name: "fenced without language", text: "```\n[1, 2]\n```", want: `[1, 2]`},
+		{name: "prose before and after", text: "好的，评估结果如下：\n{\"score\": 80}\n希望对你有帮助。", want: `{"score": 80}`},
+		{name: "prose around fence", text: "结果：\n```json\n{\"score\": 80}\n```\n以上。", want: `{"score": 80}`},
+		{name: "trailing commas", text: `{"items": [1, 2, ], "score": 80, }`, want: `{"items": [1, 2 ], "score": 80 }`},
+		{name: "comma inside string kept", text: `{"text": "a, }", "n": 1,}`, want: `{"text": "a, }", "n": 1}`},
+		{name: "brackets inside string", text: `{"text": "[x] {y}"}`, want: `{"text": "[x] {y}"}`},
+		{name: "escaped quote inside string", text: `{"text": "say \"}\""}`, want: `{"text": "say \"}\""}`},
+		{name: "bracketed prose before JSON", text: "[注意] 以下是结果：{\"score\": 80}", want: `{"score": 80}`},
+		{name: "bracketed prose with braces", text: "根据{岗位要求}评估：\n{\"score\": 80}", want: `{"score": 80}`},
+		{name: "array", text: "题目：[{\"q\": \"a\"}, {\"q\": \"b\"},]", want: `[{"q": "a"}, {"q": "b"}]`},
+		{name: "invalid JSON returned for decode error", text: `结果：{score: 80}`, want: `{score: 80}`},
+		{name: "no JSON", text: "抱歉，我无法完成", wantErr: true},
+		{name: "unterminated", text: `{"score": 80`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractJSON(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// scoreResult 测试用的结构化输出，分数需在0-100之间
+type scoreResult struct {
+	Score int `json:"score"`
+}
+
+func (r *scoreResult) Validate() error {
+	if r.Score < 0 || r.Score > 100 {
+		return errors.New("score out of range")
+	}
+	return nil
+}
+
+func TestDecodeStructured(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    int
+		wantErr bool
+	}{
+		{name: "valid", text: "```json\n{\"score\": 80,}\n```", want: 80},
+		{name: "validation failed", text: `{"score": 120}`, wantErr: true},
+		{name: "wrong type", text: `{"score": "80"}`, wantErr: true},
+		{name: "no JSON", text: "没有结果", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out scoreResult
+			err := DecodeStructured(tt.text, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeStructured() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && out.Score != tt.want {
+				t.Errorf("score = %d, want %d", out.Score, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalConfig 本地文件系统存储配置
+type LocalConfig struct {
+	Root string // 存储根目录
+	// BaseURL 签名下载链接的前缀，对应提供下载的接口，如 /api/files
+	BaseURL    string
+	SigningKey string
+}
+
+// LocalStorage 本地文件系统存储，下载链接由本服务校验签名后提供，只适用于单实例部署或共享目录
+type LocalStorage struct {
+	config LocalConfig
+}
+
+// NewLocalStorage 创建本地文件系统存储
+func NewLocalStorage(config LocalConfig) *LocalStorage {
+	return &LocalStorage{config: config}
+}
+
+// Put 实现Storage接口
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// 先写入临时文件再重命名，避免读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save file: %v", err)
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Get 实现Storage接口
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete 实现Storage接口
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL 实现Storage接口，生成指向 BaseURL 的链接，由 Verify 校验
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, opts URLOptions) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(opts.Expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	if opts.FileName != "" {
+		query.Set("filename", opts.FileName)
+	}
+	if opts.ContentType != "" {
+		query.Set("content_type", opts.ContentType)
+	}
+	query.Set("signature", s.sign(key, query))
+	return strings.TrimRight(s.config.BaseURL, "/") + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Verify 校验下载链接的签名和有效期，返回下载时使用的文件名和内容类型
+func (s *LocalStorage) Verify(key string, query url.Values) (URLOptions, error) {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return URLOptions{}, ErrInvalidSignature
+	}
+	expected := s.sign(key, query)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return URLOptions{}, ErrInvalidSignature
+	}
+
+	opts := URLOptions{FileName: query.Get("filename"), ContentType: query.Get("content_type")}
+	if opts.FileName == "" {
+		opts.FileName = path.Base(key)
+	}
+	return opts, nil
+}
+
+// sign 对对象键和链接参数计算HMAC-SHA256签名
+func (s *LocalStorage) sign(key string, query url.Values) string {
+	mac := hmac.New(sha256.New, []byte(s.config.SigningKey))
+	for _, part := range []string{key, query.Get("expires"), query.Get("filename"), query.Get("content_type")} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path 将对象键转换为根目录下的文件路径，拒绝跳出根目录的键
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return filepath.Join(s.config.Root, filepath.FromSlash(clean)), nil
+}
+
+// escapeKey 对对象键的每一段进行URL编码
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config S3兼容存储配置，适用于 AWS S3、MinIO 等
+type S3Config struct {
+	Endpoint  string // 不含协议，如 localhost:9000、s3.amazonaws.com
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	// Region 配置后生成下载链接时不再请求存储桶所在区域
+	Region string
+	UseSSL bool
+}
+
+// S3Storage S3兼容存储，下载链接为对象存储的预签名链接
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Storage 创建S3兼容存储，不会连接对象存储
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %v", err)
+	}
+	return &S3Storage{client: client, bucket: config.Bucket}, nil
+}
+
+// Put 实现Storage接口
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload object: %v", err)
+	}
+	return nil
+}
+
+// Get 实现Storage接口
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %v", err)
+	}
+	// GetObject 在首次读取时才发起请求，先获取元数据以便区分对象不存在
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get object: %v", err)
+	}
+	return object, nil
+}
+
+// Delete 实现Storage接口
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object: %v", err)
+	}
+	return nil
+}
+
+// SignedURL 实现Storage接口，通过响应头参数指定下载文件名和内容类型
+func (s *S3Storage) SignedURL(ctx context.Context, key string, opts URLOptions) (string, error) {
+	fileName := opts.FileName
+	if fileName == "" {
+		fileName = path.Base(key)
+	}
+	params := url.Values{}
+	params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	if opts.ContentType != "" {
+		params.Set("response-content-type", opts.ContentType)
+	}
+
+	signed, err := s.client.PresignedGetObject(ctx, s.bucket, key, opts.Expiry, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign url: %v", err)
+	}
+	return signed.String(), nil
+}
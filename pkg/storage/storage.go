@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound 文件不存在
+var ErrNotFound = errors.New("file not found")
+
+// ErrInvalidSignature 下载链接的签名无效或已过期
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// Storage 文件存储，文件以对象键标识，键使用 / 分隔
+type Storage interface {
+	// Put 保存文件，size 未知时传 -1
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取文件，文件不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// SignedURL 生成带签名、到期失效的下载链接
+	SignedURL(ctx context.Context, key string, opts URLOptions) (string, error)
+}
+
+// URLOptions 下载链接选项
+type URLOptions struct {
+	Expiry      time.Duration
+	FileName    string // 下载时的文件名，为空时使用对象键的最后一段
+	ContentType string
+}
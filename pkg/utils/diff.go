@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 段落的差异状态
+const (
+	DiffAdded     = "added"
+	DiffRemoved   = "removed"
+	DiffModified  = "modified"
+	DiffUnchanged = "unchanged"
+)
+
+// 行的差异操作
+const (
+	LineEqual  = "equal"
+	LineInsert = "insert"
+	LineDelete = "delete"
+)
+
+// maxDiffCells 逐行比较的最大计算量，超过时整段视为删除后重新添加
+const maxDiffCells = 1000000
+
+// LineDiff 一行的差异
+type LineDiff struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// SectionDiff 一个段落的差异，未变化的段落不包含逐行差异
+type SectionDiff struct {
+	Heading string     `json:"heading"`
+	Status  string     `json:"status"`
+	Lines   []LineDiff `json:"lines,omitempty"`
+}
+
+// DiffDocuments 按段落比较两个版本的简历：标题相同的段落逐行比较，其余段落为新增或删除。
+// 结果按新版本的段落顺序排列，删除的段落排在旧版本中位于其后的第一个保留段落之前
+func DiffDocuments(from, to *Document) []SectionDiff {
+	oldSections, newSections := from.Sections(), to.Sections()
+
+	// 按标题匹配段落，同名标题按出现顺序对应
+	oldIndex := make(map[string]int, len(oldSections))
+	for i, key := range sectionKeys(oldSections) {
+		oldIndex[key] = i
+	}
+	matched := make([]int, len(newSections))
+	kept := make([]bool, len(oldSections))
+	for i, key := range sectionKeys(newSections) {
+		matched[i] = -1
+		if j, ok := oldIndex[key]; ok {
+			matched[i] = j
+			kept[j] = true
+		}
+	}
+
+	var diffs []SectionDiff
+	next := 0
+	removedBefore := func(end int) {
+		for ; next < end; next++ {
+			if !kept[next] {
+				diffs = append(diffs, SectionDiff{
+					Heading: oldSections[next].Heading,
+					Status:  DiffRemoved,
+					Lines:   singleOpLines(LineDelete, sectionLines(oldSections[next])),
+				})
+			}
+		}
+	}
+	for i, section := range newSections {
+		j := matched[i]
+		if j < 0 {
+			diffs = append(diffs, SectionDiff{
+				Heading: section.Heading,
+				Status:  DiffAdded,
+				Lines:   singleOpLines(LineInsert, sectionLines(section)),
+			})
+			continue
+		}
+		if j >= next {
+			removedBefore(j)
+			next = j + 1
+		}
+
+		lines := diffLines(sectionLines(oldSections[j]), sectionLines(section))
+		diff := SectionDiff{Heading: section.Heading, Status: DiffUnchanged}
+		for _, line := range lines {
+			if line.Op != LineEqual {
+				diff.Status, diff.Lines = DiffModified, lines
+				break
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	removedBefore(len(oldSections))
+	return diffs
+}
+
+// sectionKeys 返回用于匹配段落的键：忽略大小写和冒号的标题，加上同名标题的序号
+func sectionKeys(sections []Section) []string {
+	keys := make([]string, len(sections))
+	seen := map[string]int{}
+	for i, section := range sections {
+		heading := strings.ToLower(strings.Trim(strings.TrimSpace(section.Heading), ":： "))
+		keys[i] = heading + "#" + strconv.Itoa(seen[heading])
+		seen[heading]++
+	}
+	return keys
+}
+
+// sectionLines 返回段落正文的非空行，不含标题
+func sectionLines(section Section) []string {
+	var lines []string
+	for _, line := range strings.Split((&Document{Blocks: section.Blocks}).Text(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// singleOpLines 将所有行标记为同一操作
+func singleOpLines(op string, lines []string) []LineDiff {
+	diffs := make([]LineDiff, len(lines))
+	for i, line := range lines {
+		diffs[i] = LineDiff{Op: op, Text: line}
+	}
+	return diffs
+}
+
+// diffLines 基于最长公共子序列逐行比较，删除的行排在对应的新增行之前
+func diffLines(a, b []string) []LineDiff {
+	if len(a)*len(b) > maxDiffCells {
+		return append(singleOpLines(LineDelete, a), singleOpLines(LineInsert, b)...)
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diffs := make([]LineDiff, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diffs = append(diffs, LineDiff{Op: LineEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diffs = append(diffs, LineDiff{Op: LineDelete, Text: a[i]})
+			i++
+		default:
+			diffs = append(diffs, LineDiff{Op: LineInsert, Text: b[j]})
+			j++
+		}
+	}
+	diffs = append(diffs, singleOpLines(LineDelete, a[i:])...)
+	return append(diffs, singleOpLines(LineInsert, b[j:])...)
+}
@@ -0,0 +1,275 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Word 97-2003 二进制格式常量
+const (
+	docMagic           = 0xA5EC // FIB 中的 wIdent
+	docMinFib          = 101    // Word 97 起的 nFib，更早的版本不支持
+	docFlagEncrypted   = 0x0100 // FIB 标志位 fEncrypted
+	docFlagWhichTblStm = 0x0200 // FIB 标志位 fWhichTblStm，为1时使用 1Table 流
+	docFcClxIndex      = 33     // FibRgFcLcb97 中 fcClx 的序号
+	docFcCompressed    = 0x40000000
+)
+
+// ErrEncryptedDocument 加密的文档
+var ErrEncryptedDocument = errors.New("encrypted document is not supported")
+
+// parseDOC 提取Word 97-2003（CFB/OLE复合文档）的正文文本：读取FIB定位表流中的分段表，按分段拼接正文
+func parseDOC(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open document: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat document: %v", err)
+	}
+
+	streams, err := readDocStreams(f, info.Size())
+	if err != nil {
+		return "", err
+	}
+	wordDoc := streams["WordDocument"]
+	if wordDoc == nil {
+		return "", errors.New("not a word document: WordDocument stream not found")
+	}
+
+	// 解析FIB
+	if len(wordDoc) < 34 || binary.LittleEndian.Uint16(wordDoc) != docMagic {
+		return "", errors.New("invalid word document header")
+	}
+	if nFib := binary.LittleEndian.Uint16(wordDoc[2:]); nFib < docMinFib {
+		return "", fmt.Errorf("word document version is too old (nFib=%d)", nFib)
+	}
+	flags := binary.LittleEndian.Uint16(wordDoc[0x0A:])
+	if flags&docFlagEncrypted != 0 {
+		return "", ErrEncryptedDocument
+	}
+	tableName := "0Table"
+	if flags&docFlagWhichTblStm != 0 {
+		tableName = "1Table"
+	}
+	table := streams[tableName]
+	if table == nil {
+		return "", fmt.Errorf("invalid word document: %s stream not found", tableName)
+	}
+
+	ccpText, fcClx, lcbClx, err := readFib(wordDoc)
+	if err != nil {
+		return "", err
+	}
+	if uint64(fcClx)+uint64(lcbClx) > uint64(len(table)) {
+		return "", errors.New("invalid word document: piece table out of range")
+	}
+	pieces, err := readPieceTable(table[fcClx : fcClx+lcbClx])
+	if err != nil {
+		return "", err
+	}
+
+	// 按分段读取正文
+	var text []rune
+	for _, p := range pieces {
+		if p.cpStart >= ccpText {
+			break
+		}
+		cpEnd := p.cpEnd
+		if cpEnd > ccpText {
+			cpEnd = ccpText
+		}
+		chars, err := p.read(wordDoc, cpEnd-p.cpStart)
+		if err != nil {
+			return "", err
+		}
+		text = append(text, chars...)
+	}
+
+	return cleanDocText(text), nil
+}
+
+// readDocStreams 读取复合文档根目录下的所有流；各流的数据都保存在文件中，
+// 目录中声明的大小合计超过文件大小 limit 时视为伪造的文档，不按声明的大小分配内存
+func readDocStreams(ra io.ReaderAt, limit int64) (map[string][]byte, error) {
+	doc, err := mscfb.New(ra)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compound document: %v", err)
+	}
+	streams := make(map[string][]byte)
+	var total int64
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if len(entry.Path) > 0 || entry.Size <= 0 {
+			continue
+		}
+		if total += entry.Size; total > limit {
+			return nil, fmt.Errorf("invalid compound document: stream %s exceeds file size", entry.Name)
+		}
+		data := make([]byte, entry.Size)
+		if _, err := io.ReadFull(entry, data); err != nil {
+			return nil, fmt.Errorf("failed to read stream %s: %v", entry.Name, err)
+		}
+		streams[entry.Name] = data
+	}
+	return streams, nil
+}
+
+// readFib 从FIB中读取正文字符数和分段表（Clx）的位置
+func readFib(wordDoc []byte) (ccpText, fcClx, lcbClx uint32, err error) {
+	invalid := errors.New("invalid word document: truncated FIB")
+
+	pos := 32 // FibBase
+	csw := int(binary.LittleEndian.Uint16(wordDoc[pos:]))
+	pos += 2 + csw*2 // fibRgW
+	if pos+2 > len(wordDoc) {
+		return 0, 0, 0, invalid
+	}
+	cslw := int(binary.LittleEndian.Uint16(wordDoc[pos:]))
+	pos += 2
+	if cslw < 4 || pos+cslw*4+2 > len(wordDoc) {
+		return 0, 0, 0, invalid
+	}
+	ccpText = binary.LittleEndian.Uint32(wordDoc[pos+3*4:]) // fibRgLw.ccpText
+	pos += cslw * 4
+	cbRgFcLcb := int(binary.LittleEndian.Uint16(wordDoc[pos:]))
+	pos += 2
+	if cbRgFcLcb <= docFcClxIndex || pos+(docFcClxIndex+1)*8 > len(wordDoc) {
+		return 0, 0, 0, invalid
+	}
+	fcClx = binary.LittleEndian.Uint32(wordDoc[pos+docFcClxIndex*8:])
+	lcbClx = binary.LittleEndian.Uint32(wordDoc[pos+docFcClxIndex*8+4:])
+	return ccpText, fcClx, lcbClx, nil
+}
+
+// docPiece 分段表中的一段文本
+type docPiece struct {
+	cpStart, cpEnd uint32
+	fc             uint32 // 在 WordDocument 流中的偏移
+	compressed     bool   // 为 true 时每个字符占1字节（cp1252），否则为UTF-16LE
+}
+
+// readPieceTable 解析Clx，跳过其中的Prc，读取Pcdt中的分段
+func readPieceTable(clx []byte) ([]docPiece, error) {
+	invalid := errors.New("invalid word document: bad piece table")
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 { // Prc
+		if pos+3 > len(clx) {
+			return nil, invalid
+		}
+		// cbGrpprl 按无符号数读取，每个Prc至少前进3字节，伪造的负数长度不会导致死循环或越界
+		pos += 3 + int(binary.LittleEndian.Uint16(clx[pos+1:]))
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 { // Pcdt
+		return nil, invalid
+	}
+	lcb := int(binary.LittleEndian.Uint32(clx[pos+1:]))
+	plc := clx[pos+5:]
+	if lcb > len(plc) || lcb < 4 || (lcb-4)%12 != 0 {
+		return nil, invalid
+	}
+
+	// PlcPcd：n+1 个字符位置，随后是 n 个8字节的Pcd
+	n := (lcb - 4) / 12
+	pieces := make([]docPiece, n)
+	for i := 0; i < n; i++ {
+		pcd := plc[(n+1)*4+i*8:]
+		fc := binary.LittleEndian.Uint32(pcd[2:])
+		pieces[i] = docPiece{
+			cpStart:    binary.LittleEndian.Uint32(plc[i*4:]),
+			cpEnd:      binary.LittleEndian.Uint32(plc[(i+1)*4:]),
+			fc:         fc &^ docFcCompressed,
+			compressed: fc&docFcCompressed != 0,
+		}
+		if pieces[i].compressed {
+			pieces[i].fc /= 2
+		}
+	}
+	return pieces, nil
+}
+
+// read 从 WordDocument 流中读取该段的 count 个字符
+func (p docPiece) read(wordDoc []byte, count uint32) ([]rune, error) {
+	size := uint64(count)
+	if !p.compressed {
+		size *= 2
+	}
+	if uint64(p.fc)+size > uint64(len(wordDoc)) {
+		return nil, errors.New("invalid word document: text out of range")
+	}
+	data := wordDoc[p.fc : uint64(p.fc)+size]
+
+	if p.compressed {
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode text: %v", err)
+		}
+		return []rune(string(decoded)), nil
+	}
+	units := make([]uint16, count)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return utf16.Decode(units), nil
+}
+
+// cleanDocText 处理正文中的控制字符：段落、换行、单元格标记转为换行或制表符，域只保留显示结果
+func cleanDocText(text []rune) string {
+	var b strings.Builder
+	// 域的嵌套层级，以及每层是否已到达显示结果部分
+	var fields []bool
+	var prev rune
+	for _, r := range text {
+		last := prev
+		prev = r
+		switch r {
+		case 0x13: // 域开始
+			fields = append(fields, false)
+			continue
+		case 0x14: // 域分隔符，之后是显示结果
+			if len(fields) > 0 {
+				fields[len(fields)-1] = true
+			}
+			continue
+		case 0x15: // 域结束
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+		// 跳过域代码
+		if len(fields) > 0 && !fields[len(fields)-1] {
+			continue
+		}
+
+		switch r {
+		case '\r', 0x0B, 0x0C: // 段落结束、手动换行、分页符
+			b.WriteRune('\n')
+		case 0x07: // 单元格结束；紧跟在单元格结束之后的是行结束
+			if last == 0x07 {
+				b.WriteRune('\n')
+			} else {
+				b.WriteRune('\t')
+			}
+		case 0x1E: // 不间断连字符
+			b.WriteRune('-')
+		case 0xA0:
+			b.WriteRune(' ')
+		case 0x01, 0x08, 0x1F: // 嵌入对象、绘图对象、可选连字符
+		default:
+			if r >= 0x20 || r == '\t' {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
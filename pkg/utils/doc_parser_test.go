@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// prc 构造一个 cbGrpprl 为 size 的Prc，grpprl 内容填0
+func prc(size uint16, grpprl int) []byte {
+	b := []byte{0x01, 0, 0}
+	binary.LittleEndian.PutUint16(b[1:], size)
+	return append(b, make([]byte, grpprl)...)
+}
+
+// pcdt 构造包含给定分段的Pcdt，cps 为 n+1 个字符位置，fcs 为 n 个Pcd的fc
+func pcdt(cps []uint32, fcs []uint32) []byte {
+	var plc []byte
+	for _, cp := range cps {
+		plc = binary.LittleEndian.AppendUint32(plc, cp)
+	}
+	for _, fc := range fcs {
+		plc = append(plc, 0, 0)
+		plc = binary.LittleEndian.AppendUint32(plc, fc)
+		plc = append(plc, 0, 0)
+	}
+	b := []byte{0x02}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(plc)))
+	return append(b, plc...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func TestReadPieceTable(t *testing.T) {
+	single := pcdt([]uint32{0, 5}, []uint32{0x800 | docFcCompressed})
+	want := []docPiece{{cpStart: 0, cpEnd: 5, fc: 0x400, compressed: true}}
+
+	tests := []struct {
+		name    string
+		clx     []byte
+		want    []docPiece
+		wantErr bool
+	}{
+		{name: "single compressed piece", clx: single, want: want},
+		{
+			name: "two pieces",
+			clx:  pcdt([]uint32{0, 3, 7}, []uint32{0x100, 0x200 | docFcCompressed}),
+			want: []docPiece{
+				{cpStart: 0, cpEnd: 3, fc: 0x100},
+				{cpStart: 3, cpEnd: 7, fc: 0x100, compressed: true},
+			},
+		},
+		{name: "prc before pcdt", clx: concat(prc(4, 4), prc(0, 0), single), want: want},
+		// cbGrpprl 为 0xFFFD 时按有符号数解释为 -3，曾导致位置不变而死循环
+		{name: "prc size 0xFFFD", clx: concat(prc(0xFFFD, 0), single), wantErr: true},
+		// 负数长度曾使位置回退并越界
+		{name: "prc size 0x8000", clx: concat(prc(0x8000, 0), single), wantErr: true},
+		{name: "prc size beyond clx", clx: concat(prc(100, 2), single), wantErr: true},
+		{name: "truncated prc", clx: []byte{0x01, 0x00}, wantErr: true},
+		{name: "empty", clx: nil, wantErr: true},
+		{name: "missing pcdt", clx: []byte{0x03, 0, 0, 0, 0}, wantErr: true},
+		{name: "lcb beyond clx", clx: single[:len(single)-1], wantErr: true},
+		{name: "lcb not a plc", clx: concat([]byte{0x02, 6, 0, 0, 0}, make([]byte, 6)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPieceTable(tt.clx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("readPieceTable() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readPieceTable() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readPieceTable() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
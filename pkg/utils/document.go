@@ -0,0 +1,375 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// BlockType 文档块的类型
+type BlockType string
+
+// 文档块类型
+const (
+	BlockHeading   BlockType = "heading"
+	BlockParagraph BlockType = "paragraph"
+	BlockListItem  BlockType = "list_item"
+	BlockTable     BlockType = "table"
+)
+
+// sectionTitles 简历中常见的段落标题，用于识别没有使用标题样式的标题行，英文标题为小写
+var sectionTitles = []string{
+	"教育经历", "教育背景", "工作经历", "工作经验", "实习经历", "项目经验", "项目经历", "校园经历", "在校经历",
+	"专业技能", "技能特长", "个人技能", "技术栈", "个人信息", "基本信息", "联系方式", "求职意向",
+	"自我评价", "个人简介", "个人总结", "个人优势", "资格证书", "技能证书", "证书资质", "荣誉奖项", "获奖情况", "获奖经历", "培训经历",
+	"education", "academic background", "experience", "work experience", "professional experience", "employment history",
+	"internships", "projects", "skills", "technical skills", "summary", "profile", "objective", "contact",
+	"certifications", "certificates", "awards", "honors", "publications", "languages",
+}
+
+// Block 文档中的一个块
+type Block struct {
+	Type    BlockType  `json:"type"`
+	Level   int        `json:"level,omitempty"`   // 标题级别（1-6）或列表嵌套层级（从0开始）
+	Ordered bool       `json:"ordered,omitempty"` // 是否为有序列表
+	Text    string     `json:"text,omitempty"`
+	Rows    [][]string `json:"rows,omitempty"` // 表格的单元格文本
+}
+
+// Section 以标题划分的段落
+type Section struct {
+	Heading string  `json:"heading"` // 文档开头没有标题的部分为空
+	Level   int     `json:"level"`
+	Blocks  []Block `json:"blocks"`
+}
+
+// Document 解析简历得到的结构化中间文档，保留标题、列表和表格
+type Document struct {
+	Blocks []Block `json:"blocks"`
+}
+
+// TextDocument 由纯文本构建文档：每个非空行为一个块，识别项目符号列表和常见的段落标题
+func TextDocument(text string) *Document {
+	doc := &Document{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r　")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " \t　")
+		if item, ok := trimBullet(trimmed); ok {
+			indent := utf8.RuneCountInString(line) - utf8.RuneCountInString(trimmed)
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockListItem, Level: indent / 2, Text: item})
+			continue
+		}
+		doc.Blocks = append(doc.Blocks, Block{Type: BlockParagraph, Text: trimmed})
+	}
+	doc.InferHeadings()
+	return doc
+}
+
+// trimBullet 去掉行首的项目符号，返回是否为列表项
+func trimBullet(line string) (string, bool) {
+	for _, bullet := range []string{"- ", "* ", "+ ", "• ", "● ", "▪ ", "■ ", "◆ ", "•", "●", "▪", "■", "◆"} {
+		if strings.HasPrefix(line, bullet) {
+			if item := strings.TrimSpace(strings.TrimPrefix(line, bullet)); item != "" {
+				return item, true
+			}
+		}
+	}
+	return line, false
+}
+
+// InferHeadings 将与常见段落标题一致的短段落标记为二级标题，弥补简历模板不使用标题样式的情况
+func (d *Document) InferHeadings() {
+	for i, block := range d.Blocks {
+		if block.Type == BlockParagraph && isSectionTitle(block.Text) {
+			d.Blocks[i].Type = BlockHeading
+			d.Blocks[i].Level = 2
+		}
+	}
+}
+
+// isSectionTitle 判断文本是否为段落标题，允许带有冒号、括号等装饰字符，英文不区分大小写
+func isSectionTitle(text string) bool {
+	text = strings.ToLower(strings.Trim(strings.TrimSpace(text), ":：|/【】[]<>《》—-_ 　"))
+	if text == "" {
+		return false
+	}
+	for _, title := range sectionTitles {
+		if strings.Contains(text, title) && utf8.RuneCountInString(text) <= utf8.RuneCountInString(title)+4 {
+			return true
+		}
+	}
+	return false
+}
+
+// Text 将文档渲染为纯文本：每个块一行，列表项带项目符号，表格单元格以制表符分隔
+func (d *Document) Text() string {
+	var b strings.Builder
+	counters := map[int]int{}
+	for i, block := range d.Blocks {
+		if block.Type == BlockHeading && i > 0 {
+			b.WriteString("\n")
+		}
+		if block.Type != BlockListItem {
+			counters = map[int]int{}
+		}
+		switch block.Type {
+		case BlockListItem:
+			b.WriteString(strings.Repeat("  ", block.Level))
+			b.WriteString(listMarker(block, counters))
+			b.WriteString(block.Text)
+			b.WriteString("\n")
+		case BlockTable:
+			for _, row := range block.Rows {
+				b.WriteString(strings.Join(row, "\t"))
+				b.WriteString("\n")
+			}
+		default:
+			b.WriteString(block.Text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Markdown 将文档渲染为Markdown，供大模型提示词使用，标题层级和表格结构比纯文本更清晰
+func (d *Document) Markdown() string {
+	var b strings.Builder
+	counters := map[int]int{}
+	for i, block := range d.Blocks {
+		prevList := i > 0 && d.Blocks[i-1].Type == BlockListItem
+		if i > 0 && !(block.Type == BlockListItem && prevList) {
+			b.WriteString("\n")
+		}
+		if block.Type != BlockListItem {
+			counters = map[int]int{}
+		}
+		switch block.Type {
+		case BlockHeading:
+			level := block.Level
+			if level < 1 || level > 6 {
+				level = 2
+			}
+			b.WriteString(strings.Repeat("#", level) + " " + block.Text + "\n")
+		case BlockListItem:
+			b.WriteString(strings.Repeat("  ", block.Level) + listMarker(block, counters) + block.Text + "\n")
+		case BlockTable:
+			for r, row := range block.Rows {
+				cells := make([]string, len(row))
+				for c, cell := range row {
+					cells[c] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", "\\|"), "\n", " ")
+				}
+				b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+				if r == 0 {
+					b.WriteString(strings.Repeat("| --- ", len(row)) + "|\n")
+				}
+			}
+		default:
+			b.WriteString(block.Text + "\n")
+		}
+	}
+	return b.String()
+}
+
+// listMarker 返回列表项的符号，有序列表按层级编号
+func listMarker(block Block, counters map[int]int) string {
+	for level := range counters {
+		if level > block.Level {
+			delete(counters, level)
+		}
+	}
+	if !block.Ordered {
+		return "- "
+	}
+	counters[block.Level]++
+	return strconv.Itoa(counters[block.Level]) + ". "
+}
+
+// HTML 将文档渲染为HTML片段，供预览使用，所有文本均已转义
+func (d *Document) HTML() string {
+	var b strings.Builder
+	// lists 记录当前打开的各层列表是否为有序列表
+	var lists []bool
+	closeLists := func(depth int) {
+		for len(lists) > depth {
+			if lists[len(lists)-1] {
+				b.WriteString("</li></ol>\n")
+			} else {
+				b.WriteString("</li></ul>\n")
+			}
+			lists = lists[:len(lists)-1]
+		}
+	}
+
+	for _, block := range d.Blocks {
+		if block.Type != BlockListItem {
+			closeLists(0)
+		}
+		switch block.Type {
+		case BlockHeading:
+			level := block.Level
+			if level < 1 || level > 6 {
+				level = 2
+			}
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, html.EscapeString(block.Text), level)
+		case BlockListItem:
+			depth := block.Level + 1
+			closeLists(depth)
+			if len(lists) == depth && lists[depth-1] != block.Ordered {
+				// 同一层级的列表类型变化时开始新的列表
+				closeLists(depth - 1)
+			}
+			if len(lists) == depth {
+				b.WriteString("</li>\n")
+			}
+			for len(lists) < depth {
+				lists = append(lists, block.Ordered)
+				if block.Ordered {
+					b.WriteString("<ol>\n")
+				} else {
+					b.WriteString("<ul>\n")
+				}
+				if len(lists) < depth {
+					b.WriteString("<li>")
+				}
+			}
+			b.WriteString("<li>" + html.EscapeString(block.Text))
+		case BlockTable:
+			b.WriteString("<table>\n")
+			for r, row := range block.Rows {
+				cell := "td"
+				if r == 0 {
+					cell = "th"
+				}
+				b.WriteString("<tr>")
+				for _, text := range row {
+					text = strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+					fmt.Fprintf(&b, "<%s>%s</%s>", cell, text, cell)
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</table>\n")
+		default:
+			b.WriteString("<p>" + html.EscapeString(block.Text) + "</p>\n")
+		}
+	}
+	closeLists(0)
+	return b.String()
+}
+
+// Sections 按标题划分文档，每个段落包含该标题到下一个标题之前的所有块
+func (d *Document) Sections() []Section {
+	var sections []Section
+	current := Section{}
+	for _, block := range d.Blocks {
+		if block.Type == BlockHeading {
+			if current.Heading != "" || len(current.Blocks) > 0 {
+				sections = append(sections, current)
+			}
+			current = Section{Heading: block.Text, Level: block.Level}
+			continue
+		}
+		current.Blocks = append(current.Blocks, block)
+	}
+	if current.Heading != "" || len(current.Blocks) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+// Text 将段落渲染为纯文本，包含标题行
+func (s Section) Text() string {
+	doc := &Document{Blocks: s.Blocks}
+	if s.Heading == "" {
+		return doc.Text()
+	}
+	return s.Heading + "\n" + doc.Text()
+}
+
+// tableBuilder 按行构建表格块。每个单元格只有一段文字的行作为表格行保留；单元格中包含多个段落或嵌套表格的行
+// 视为排版用的表格（中文简历模板中很常见），按单元格顺序展开为普通的块；只有一个单元格有内容的行也展开
+type tableBuilder struct {
+	blocks []Block
+	rows   [][]string
+}
+
+// addRow 添加一行，cells 为各单元格中的块
+func (t *tableBuilder) addRow(cells [][]Block) {
+	if row, ok := tableDataRow(cells); ok {
+		t.rows = append(t.rows, row)
+		return
+	}
+	t.flush()
+	for _, cell := range cells {
+		t.blocks = append(t.blocks, cell...)
+	}
+}
+
+// flush 将已累积的表格行输出为一个表格块
+func (t *tableBuilder) flush() {
+	if len(t.rows) > 0 {
+		t.blocks = append(t.blocks, Block{Type: BlockTable, Rows: t.rows})
+		t.rows = nil
+	}
+}
+
+// finish 结束表格，返回生成的块
+func (t *tableBuilder) finish() []Block {
+	t.flush()
+	return t.blocks
+}
+
+// tableDataRow 判断是否为数据行：至少两个单元格有内容，且每个单元格最多一段单行文字
+func tableDataRow(cells [][]Block) ([]string, bool) {
+	var row []string
+	filled := 0
+	for _, cell := range cells {
+		switch len(cell) {
+		case 0:
+			row = append(row, "")
+		case 1:
+			if cell[0].Type == BlockTable || strings.Contains(cell[0].Text, "\n") {
+				return nil, false
+			}
+			row = append(row, cell[0].Text)
+			filled++
+		default:
+			return nil, false
+		}
+	}
+	return row, filled >= 2
+}
+
+// Value 实现driver.Valuer接口，以JSON形式存储
+func (d *Document) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(d)
+	return string(data), err
+}
+
+// Scan 实现sql.Scanner接口
+func (d *Document) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 Document", value)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, d)
+}
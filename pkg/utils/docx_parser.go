@@ -0,0 +1,591 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WordprocessingML 命名空间，包括 Strict 格式
+const (
+	wmlNamespace       = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	wmlStrictNamespace = "http://purl.oclc.org/ooxml/wordprocessingml/main"
+)
+
+// headingStylePattern 内置标题样式名，如 heading 1
+var headingStylePattern = regexp.MustCompile(`^heading\s*([1-9])$`)
+
+// docxRels 文档部件的关系文件
+type docxRels struct {
+	Relationships []struct {
+		ID         string `xml:"Id,attr"`
+		Type       string `xml:"Type,attr"`
+		Target     string `xml:"Target,attr"`
+		TargetMode string `xml:"TargetMode,attr"`
+	} `xml:"Relationship"`
+}
+
+// docxStyles styles.xml 中的段落样式
+type docxStyles struct {
+	Styles []struct {
+		Type    string `xml:"type,attr"`
+		StyleID string `xml:"styleId,attr"`
+		Name    struct {
+			Val string `xml:"val,attr"`
+		} `xml:"name"`
+		BasedOn struct {
+			Val string `xml:"val,attr"`
+		} `xml:"basedOn"`
+		OutlineLvl *struct {
+			Val string `xml:"val,attr"`
+		} `xml:"pPr>outlineLvl"`
+	} `xml:"style"`
+}
+
+// docxNumbering numbering.xml 中的列表定义
+type docxNumbering struct {
+	AbstractNums []struct {
+		ID     string `xml:"abstractNumId,attr"`
+		Levels []struct {
+			Ilvl   string `xml:"ilvl,attr"`
+			NumFmt struct {
+				Val string `xml:"val,attr"`
+			} `xml:"numFmt"`
+		} `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		NumID         string `xml:"numId,attr"`
+		AbstractNumID struct {
+			Val string `xml:"val,attr"`
+		} `xml:"abstractNumId"`
+	} `xml:"num"`
+}
+
+// docxReader 将Word 2007及以上（OOXML）文档解析为结构化文档
+type docxReader struct {
+	files    map[string]*zip.File
+	headings map[string]int             // 样式ID → 标题级别
+	ordered  map[string]map[string]bool // 列表ID → 层级 → 是否有序
+	links    map[string]string          // 当前部件中超链接关系ID → 地址
+}
+
+// docxCell 表格单元格
+type docxCell struct {
+	blocks []Block
+	merged bool // 纵向合并的后续单元格，内容已在首个单元格中
+}
+
+// parseDOCX 解析Word 2007及以上（OOXML）文档，保留标题、列表、表格、文本框和页眉页脚
+func parseDOCX(filePath string) (*Document, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %v", err)
+	}
+	defer zr.Close()
+
+	r := &docxReader{files: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		r.files[f.Name] = f
+	}
+	if r.files["word/document.xml"] == nil {
+		return nil, errors.New("failed to open document: word/document.xml not found")
+	}
+	r.loadStyles()
+	r.loadNumbering()
+
+	rels := r.loadRels("word/document.xml")
+	body, err := r.parsePart("word/document.xml", rels)
+	if err != nil {
+		return nil, err
+	}
+
+	// 页眉页脚中常放置姓名和联系方式，多个部件内容相同时只保留一份
+	var headers, footers []Block
+	seen := make(map[string]bool)
+	for _, rel := range rels.Relationships {
+		isHeader := strings.HasSuffix(rel.Type, "/header")
+		if !isHeader && !strings.HasSuffix(rel.Type, "/footer") {
+			continue
+		}
+		name := path.Join("word", rel.Target)
+		blocks, err := r.parsePart(name, r.loadRels(name))
+		if err != nil {
+			continue
+		}
+		key := (&Document{Blocks: blocks}).Text()
+		if strings.TrimSpace(key) == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if isHeader {
+			headers = append(headers, blocks...)
+		} else {
+			footers = append(footers, blocks...)
+		}
+	}
+
+	return &Document{Blocks: append(append(headers, body...), footers...)}, nil
+}
+
+// open 读取压缩包中的部件
+func (r *docxReader) open(name string) ([]byte, error) {
+	f := r.files[name]
+	if f == nil {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// loadRels 读取部件的关系文件
+func (r *docxReader) loadRels(part string) *docxRels {
+	rels := &docxRels{}
+	data, err := r.open(path.Join(path.Dir(part), "_rels", path.Base(part)+".rels"))
+	if err == nil {
+		xml.Unmarshal(data, rels)
+	}
+	return rels
+}
+
+// loadStyles 读取标题样式，样式名为 heading N、Title 或设置了大纲级别的样式视为标题，并沿继承链查找
+func (r *docxReader) loadStyles() {
+	r.headings = make(map[string]int)
+	data, err := r.open("word/styles.xml")
+	if err != nil {
+		return
+	}
+	styles := &docxStyles{}
+	if err := xml.Unmarshal(data, styles); err != nil {
+		return
+	}
+
+	own := make(map[string]int)
+	basedOn := make(map[string]string)
+	for _, s := range styles.Styles {
+		if s.Type != "" && s.Type != "paragraph" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(s.Name.Val))
+		switch {
+		case name == "title":
+			own[s.StyleID] = 1
+		case headingStylePattern.MatchString(name):
+			own[s.StyleID], _ = strconv.Atoi(headingStylePattern.FindStringSubmatch(name)[1])
+		case s.OutlineLvl != nil:
+			if lvl, err := strconv.Atoi(s.OutlineLvl.Val); err == nil && lvl < 9 {
+				own[s.StyleID] = lvl + 1
+			}
+		}
+		basedOn[s.StyleID] = s.BasedOn.Val
+	}
+	for id := range basedOn {
+		for cur, depth := id, 0; cur != "" && depth < 10; cur, depth = basedOn[cur], depth+1 {
+			if level, ok := own[cur]; ok {
+				r.headings[id] = level
+				break
+			}
+		}
+	}
+}
+
+// loadNumbering 读取列表定义，判断每个列表层级是有序编号还是项目符号
+func (r *docxReader) loadNumbering() {
+	r.ordered = make(map[string]map[string]bool)
+	data, err := r.open("word/numbering.xml")
+	if err != nil {
+		return
+	}
+	numbering := &docxNumbering{}
+	if err := xml.Unmarshal(data, numbering); err != nil {
+		return
+	}
+
+	abstract := make(map[string]map[string]bool)
+	for _, a := range numbering.AbstractNums {
+		levels := make(map[string]bool)
+		for _, lvl := range a.Levels {
+			levels[lvl.Ilvl] = lvl.NumFmt.Val != "" && lvl.NumFmt.Val != "bullet" && lvl.NumFmt.Val != "none"
+		}
+		abstract[a.ID] = levels
+	}
+	for _, n := range numbering.Nums {
+		r.ordered[n.NumID] = abstract[n.AbstractNumID.Val]
+	}
+}
+
+// parsePart 解析正文、页眉或页脚部件
+func (r *docxReader) parsePart(name string, rels *docxRels) ([]Block, error) {
+	data, err := r.open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	r.links = make(map[string]string)
+	for _, rel := range rels.Relationships {
+		if strings.HasSuffix(rel.Type, "/hyperlink") && rel.TargetMode == "External" {
+			r.links[rel.ID] = rel.Target
+		}
+	}
+
+	d := xml.NewDecoder(strings.NewReader(string(data)))
+	blocks, err := r.blocks(d)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+	return blocks, nil
+}
+
+// blocks 读取块级内容直到当前元素结束，嵌套的内容控件等容器元素直接展开
+func (r *docxReader) blocks(d *xml.Decoder) ([]Block, error) {
+	var blocks []Block
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return blocks, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			parsed, handled, err := r.blockElement(d, t)
+			if err != nil {
+				return blocks, err
+			}
+			if handled {
+				blocks = append(blocks, parsed...)
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return blocks, nil
+			}
+			depth--
+		}
+	}
+}
+
+// blockElement 处理段落、表格等块级元素，返回是否已处理（已读取到元素结束）
+func (r *docxReader) blockElement(d *xml.Decoder, start xml.StartElement) ([]Block, bool, error) {
+	if !isWML(start.Name) {
+		// 兼容内容的替代版本与首选版本重复，只保留首选版本
+		if start.Name.Local == "Fallback" {
+			return nil, true, d.Skip()
+		}
+		return nil, false, nil
+	}
+	switch start.Name.Local {
+	case "p":
+		blocks, err := r.paragraph(d)
+		return blocks, true, err
+	case "tbl":
+		blocks, err := r.table(d)
+		return blocks, true, err
+	case "sectPr", "del", "moveFrom":
+		return nil, true, d.Skip()
+	}
+	return nil, false, nil
+}
+
+// paragraph 读取段落，返回段落本身以及其中文本框的内容
+func (r *docxReader) paragraph(d *xml.Decoder) ([]Block, error) {
+	var text strings.Builder
+	var extra []Block
+	style, numID, ilvl, outline := "", "", 0, -1
+
+	// 超链接的起始位置和地址
+	type link struct {
+		depth int
+		pos   int
+		url   string
+	}
+	var links []link
+
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !isWML(t.Name) {
+				if t.Name.Local == "Fallback" {
+					if err := d.Skip(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				depth++
+				continue
+			}
+			switch t.Name.Local {
+			case "pPr":
+				if style, numID, ilvl, outline, err = paragraphProps(d); err != nil {
+					return nil, err
+				}
+			case "t":
+				var s string
+				if err := d.DecodeElement(&s, &t); err != nil {
+					return nil, err
+				}
+				text.WriteString(s)
+			case "tab", "ptab":
+				text.WriteString("\t")
+				depth++
+			case "br", "cr":
+				text.WriteString("\n")
+				depth++
+			case "noBreakHyphen":
+				text.WriteString("-")
+				depth++
+			case "rPr", "delText", "instrText", "del", "moveFrom", "footnoteReference", "endnoteReference":
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			case "txbxContent":
+				blocks, err := r.blocks(d)
+				if err != nil {
+					return nil, err
+				}
+				extra = append(extra, blocks...)
+			case "hyperlink":
+				url := r.links[wmlAttr(t, "id")]
+				links = append(links, link{depth: depth, pos: text.Len(), url: url})
+				depth++
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				blocks := r.paragraphBlocks(text.String(), style, numID, ilvl, outline)
+				return append(blocks, extra...), nil
+			}
+			depth--
+			// 超链接文字与地址不同时附上地址
+			if n := len(links); n > 0 && isWML(t.Name) && t.Name.Local == "hyperlink" && links[n-1].depth == depth {
+				l := links[n-1]
+				links = links[:n-1]
+				label := strings.TrimSpace(text.String()[l.pos:])
+				if l.url != "" && label != l.url && !strings.Contains(label, strings.TrimPrefix(l.url, "mailto:")) {
+					text.WriteString(" (" + strings.TrimPrefix(l.url, "mailto:") + ")")
+				}
+			}
+		}
+	}
+}
+
+// paragraphProps 读取段落属性中的样式、列表和大纲级别
+func paragraphProps(d *xml.Decoder) (style, numID string, ilvl, outline int, err error) {
+	outline = -1
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return style, numID, ilvl, outline, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch t.Name.Local {
+			case "pStyle":
+				style = wmlAttr(t, "val")
+			case "numId":
+				numID = wmlAttr(t, "val")
+			case "ilvl":
+				ilvl, _ = strconv.Atoi(wmlAttr(t, "val"))
+			case "outlineLvl":
+				if lvl, err := strconv.Atoi(wmlAttr(t, "val")); err == nil && lvl < 9 {
+					outline = lvl
+				}
+			case "rPr":
+				depth--
+				if err := d.Skip(); err != nil {
+					return style, numID, ilvl, outline, err
+				}
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				return style, numID, ilvl, outline, nil
+			}
+			depth--
+		}
+	}
+}
+
+// paragraphBlocks 根据段落样式和列表属性生成块，空段落不生成
+func (r *docxReader) paragraphBlocks(text, style, numID string, ilvl, outline int) []Block {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	level := r.headings[style]
+	if outline >= 0 {
+		level = outline + 1
+	}
+	switch {
+	case level > 0:
+		return []Block{{Type: BlockHeading, Level: level, Text: text}}
+	case numID != "" && numID != "0":
+		return []Block{{Type: BlockListItem, Level: ilvl, Ordered: r.ordered[numID][strconv.Itoa(ilvl)], Text: text}}
+	}
+	return []Block{{Type: BlockParagraph, Text: text}}
+}
+
+// table 读取表格，排版用的行按单元格顺序展开，见 tableBuilder
+func (r *docxReader) table(d *xml.Decoder) ([]Block, error) {
+	tb := &tableBuilder{}
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if isWML(t.Name) && t.Name.Local == "tr" {
+				cells, err := r.tableRow(d)
+				if err != nil {
+					return nil, err
+				}
+				tb.addRow(cells)
+				continue
+			}
+			if isWML(t.Name) && (t.Name.Local == "tblPr" || t.Name.Local == "tblGrid") {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return tb.finish(), nil
+			}
+			depth--
+		}
+	}
+}
+
+// tableRow 读取表格行中各单元格的内容，跳过纵向合并的后续单元格
+func (r *docxReader) tableRow(d *xml.Decoder) ([][]Block, error) {
+	var cells [][]Block
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if isWML(t.Name) && t.Name.Local == "tc" {
+				cell, err := r.tableCell(d)
+				if err != nil {
+					return nil, err
+				}
+				if !cell.merged {
+					cells = append(cells, cell.blocks)
+				}
+				continue
+			}
+			if isWML(t.Name) && t.Name.Local == "trPr" {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return cells, nil
+			}
+			depth--
+		}
+	}
+}
+
+// tableCell 读取单元格内容，并识别纵向合并的后续单元格
+func (r *docxReader) tableCell(d *xml.Decoder) (docxCell, error) {
+	var cell docxCell
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return cell, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if isWML(t.Name) && t.Name.Local == "tcPr" {
+				merged, err := mergedCell(d)
+				if err != nil {
+					return cell, err
+				}
+				cell.merged = merged
+				continue
+			}
+			blocks, handled, err := r.blockElement(d, t)
+			if err != nil {
+				return cell, err
+			}
+			if handled {
+				cell.blocks = append(cell.blocks, blocks...)
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return cell, nil
+			}
+			depth--
+		}
+	}
+}
+
+// mergedCell 读取单元格属性，判断是否为纵向合并的后续单元格
+func mergedCell(d *xml.Decoder) (bool, error) {
+	merged := false
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return merged, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "vMerge" {
+				if val := wmlAttr(t, "val"); val == "" || val == "continue" {
+					merged = true
+				}
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return merged, nil
+			}
+			depth--
+		}
+	}
+}
+
+// isWML 判断元素是否属于 WordprocessingML 命名空间
+func isWML(name xml.Name) bool {
+	return name.Space == wmlNamespace || name.Space == wmlStrictNamespace
+}
+
+// wmlAttr 返回元素的属性值，忽略命名空间
+func wmlAttr(start xml.StartElement, local string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
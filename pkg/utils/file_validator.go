@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// ErrFileRejected 文件未通过安全检查，错误信息中包含具体原因
+var ErrFileRejected = errors.New("文件未通过安全检查")
+
+// maxFileNameLength 文件名（含扩展名）的最大字符数
+const maxFileNameLength = 100
+
+// extensionTypes 各扩展名允许的文件内容类型，文本类扩展名允许 text/plain 及其子类型
+var extensionTypes = map[string][]string{
+	".docx":     {ContentTypeDOCX},
+	".doc":      {ContentTypeDOC, ContentTypeOLE},
+	".pdf":      {ContentTypePDF},
+	".txt":      {ContentTypeText},
+	".md":       {ContentTypeText},
+	".markdown": {ContentTypeText},
+	".html":     {ContentTypeText},
+	".htm":      {ContentTypeText},
+}
+
+// FileLimits 上传文件的安全检查限制，值为0时不限制
+type FileLimits struct {
+	MaxZipEntries       int   // 压缩包（如 .docx）中的最大文件数
+	MaxUncompressedSize int64 // 压缩包解压后的最大总大小（字节）
+}
+
+// ValidateFile 按文件内容识别类型并检查是否与扩展名一致，压缩格式的文档检查文件数和解压后的大小，
+// 返回识别出的内容类型；未通过检查时返回包装了 ErrFileRejected 的错误
+func ValidateFile(filePath, ext string, limits FileLimits) (string, error) {
+	allowed, ok := extensionTypes[strings.ToLower(ext)]
+	if !ok {
+		return "", fmt.Errorf("%w: 不支持的文件类型 %s", ErrFileRejected, ext)
+	}
+
+	mtype, err := mimetype.DetectFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect file type: %v", err)
+	}
+	contentType := baseMediaType(mtype.String())
+	if !isAnyType(mtype, allowed) {
+		return "", fmt.Errorf("%w: 文件内容（%s）与扩展名 %s 不符", ErrFileRejected, contentType, ext)
+	}
+
+	if isAnyType(mtype, []string{"application/zip"}) {
+		if err := checkZip(filePath, limits); err != nil {
+			return "", err
+		}
+	}
+	return contentType, nil
+}
+
+// isAnyType 判断内容类型是否为任一类型或其子类型
+func isAnyType(mtype *mimetype.MIME, types []string) bool {
+	for ; mtype != nil; mtype = mtype.Parent() {
+		for _, t := range types {
+			if mtype.Is(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkZip 根据压缩包目录检查文件数和解压后的总大小，防止压缩炸弹；
+// 目录中的大小不可信，但 archive/zip 读取时会拒绝超出声明大小的内容，解析时实际读取的数据不会超过这里检查的总量
+func checkZip(filePath string, limits FileLimits) error {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return fmt.Errorf("%w: 文档已损坏（%v）", ErrFileRejected, err)
+	}
+	defer zr.Close()
+
+	if limits.MaxZipEntries > 0 && len(zr.File) > limits.MaxZipEntries {
+		return fmt.Errorf("%w: 文档包含 %d 个部件，超过上限 %d", ErrFileRejected, len(zr.File), limits.MaxZipEntries)
+	}
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if limits.MaxUncompressedSize > 0 && total > uint64(limits.MaxUncompressedSize) {
+			return fmt.Errorf("%w: 文档解压后超过 %d 字节", ErrFileRejected, limits.MaxUncompressedSize)
+		}
+	}
+	return nil
+}
+
+// SanitizeFileName 清理客户端提供的文件名：去掉路径，替换控制字符和文件系统保留字符，限制长度并保留扩展名；
+// 清理后为空时使用 fallback
+func SanitizeFileName(name, fallback string) string {
+	// 客户端可能提交 Windows 路径
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError, unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			// 控制字符和 U+202E 等格式字符可用于伪造扩展名的显示
+			return -1
+		case strings.ContainsRune(`<>:"/\|?*`, r):
+			return '_'
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return fallback
+	}
+
+	if utf8.RuneCountInString(name) > maxFileNameLength {
+		ext := filepath.Ext(name)
+		if utf8.RuneCountInString(ext) > maxFileNameLength/2 {
+			ext = ""
+		}
+		base := []rune(strings.TrimSuffix(name, ext))
+		name = strings.TrimRight(string(base[:maxFileNameLength-utf8.RuneCountInString(ext)]), " .") + ext
+	}
+	return name
+}
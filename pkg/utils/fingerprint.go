@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/bits"
+	"os"
+	"strconv"
+	"unicode"
+)
+
+// HashFile 计算文件内容的SHA-256，返回十六进制字符串
+func HashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashText 计算文本的SHA-256，返回十六进制字符串
+func HashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// 近似重复检测参数
+const (
+	simHashShingle   = 4  // SimHash 特征的长度（字符），按规范化文本的连续字符切分
+	simHashMinRunes  = 50 // 规范化后的最少字符数，过短的文本 SimHash 不稳定，不参与近似重复检测
+	simHashHexLength = 16 // 64位 SimHash 的十六进制长度
+
+	// NearDuplicateDistance 两份文本的 SimHash 汉明距离不超过该值时视为近似重复
+	NearDuplicateDistance = 8
+)
+
+// TextFingerprint 计算文本的指纹：只保留字母和数字并统一为小写后计算SHA-256，
+// 同一份简历另存为其他格式、调整排版或标点后指纹不变；没有有效内容时返回空字符串
+func TextFingerprint(text string) string {
+	normalized := normalizeText(text)
+	if len(normalized) == 0 {
+		return ""
+	}
+	return HashText(string(normalized))
+}
+
+// TextSimHash 计算文本的64位 SimHash，返回十六进制字符串：以规范化文本的连续字符为特征，
+// 修改少量词句后与原文的汉明距离很小，可用 SimHashDistance 比较；文本过短时返回空字符串
+func TextSimHash(text string) string {
+	normalized := normalizeText(text)
+	if len(normalized) < simHashMinRunes {
+		return ""
+	}
+
+	var weights [64]int
+	for i := 0; i+simHashShingle <= len(normalized); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(normalized[i : i+simHashShingle])))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, w := range weights {
+		if w > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// SimHashDistance 返回两个 SimHash 的汉明距离，任一个无效时返回 -1
+func SimHashDistance(a, b string) int {
+	if len(a) != simHashHexLength || len(b) != simHashHexLength {
+		return -1
+	}
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil {
+		return -1
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+// normalizeText 只保留字母和数字并统一为小写
+func normalizeText(text string) []rune {
+	normalized := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			normalized = append(normalized, unicode.ToLower(r))
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleResume = `张三 电话13812345678 邮箱zhangsan@example.com
+工作经历 2019-2023 北京某科技有限公司 高级后端工程师 负责订单系统的设计与开发，使用Go语言和MySQL，日均处理订单量超过一百万，主导了服务拆分和缓存优化，接口延迟降低百分之四十
+项目经历 分布式任务调度平台 使用Go和etcd实现任务分片与故障转移 支持上万个定时任务
+教育经历 2015-2019 北京大学 计算机科学与技术 本科
+技能 Go Java MySQL Redis Kafka Kubernetes Docker Linux`
+
+func TestTextFingerprint(t *testing.T) {
+	reformatted := strings.ToUpper(strings.ReplaceAll(sampleResume, "，", ", "))
+	if TextFingerprint(sampleResume) != TextFingerprint(reformatted) {
+		t.Error("TextFingerprint() differs after changing case and punctuation")
+	}
+	if TextFingerprint(sampleResume) == TextFingerprint(strings.Replace(sampleResume, "高级", "资深", 1)) {
+		t.Error("TextFingerprint() is equal after changing a word")
+	}
+	if got := TextFingerprint(" ，。\n"); got != "" {
+		t.Errorf("TextFingerprint() of punctuation = %q, want empty", got)
+	}
+}
+
+func TestTextSimHash(t *testing.T) {
+	base := TextSimHash(sampleResume)
+	if len(base) != simHashHexLength {
+		t.Fatalf("TextSimHash() = %q, want %d hex digits", base, simHashHexLength)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		similar bool
+	}{
+		{name: "reformatted", text: strings.ReplaceAll(sampleResume, "\n", "\n\n"), similar: true},
+		{name: "one word changed", text: strings.Replace(sampleResume, "高级后端工程师", "资深后端工程师", 1), similar: true},
+		{name: "skill added", text: sampleResume + " Elasticsearch", similar: true},
+		{
+			name: "unrelated",
+			text: `Jane Doe jane@example.com Senior Frontend Engineer with 6 years of experience building React and TypeScript
+applications, design systems, accessibility audits and performance tuning for large e-commerce sites.
+Education: BSc Computer Science, University of Toronto 2016.`,
+			similar: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := SimHashDistance(base, TextSimHash(tt.text))
+			if similar := d >= 0 && d <= NearDuplicateDistance; similar != tt.similar {
+				t.Errorf("distance = %d, want similar %v", d, tt.similar)
+			}
+		})
+	}
+}
+
+func TestTextSimHashShortText(t *testing.T) {
+	if got := TextSimHash("张三 Go 工程师"); got != "" {
+		t.Errorf("TextSimHash() of short text = %q, want empty", got)
+	}
+	if d := SimHashDistance("", TextSimHash(sampleResume)); d != -1 {
+		t.Errorf("SimHashDistance() with an empty hash = %d, want -1", d)
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// injectionPatterns 常见的提示词注入写法：要求忽略指令、改变角色、索要分数或伪造对话模板标记；
+// 只匹配明确的写法，避免把“扮演核心角色”“得分100”“System: Linux”这类正常表述误判为注入
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\b.{0,30}\b(?:previous|prior|above|earlier|preceding|all|your)\b.{0,20}\b(?:instructions?|prompts?|rules?|directions?)\b`),
+	regexp.MustCompile(`(?i)\b(?:you are now|from now on,? you)\b`),
+	regexp.MustCompile(`(?i)\b(?:give|assign|award)\b.{0,20}\b(?:score of 100|100 points|full marks|perfect score|maximum score|highest score)\b`),
+	regexp.MustCompile(`(?:忽略|无视|忘记|忘掉|不要理会)掉?你?(?:之前|以上|上面|前面|上述|先前|所有|全部|系统)的?.{0,6}(?:指令|指示|提示词|规则|设定)`),
+	regexp.MustCompile(`(?:你现在是|从现在开始你是|你的新(?:身份|角色|任务)是)`),
+	regexp.MustCompile(`(?:给|打|评)(?:我|出|这个答案|该答案|本答案|候选人|这份简历)?(?:一个)?(?:满分|100\s*分|最高分)`),
+	regexp.MustCompile(`<\|(?:im_start|im_end|system|endoftext)\|>|\[/?INST\]`),
+}
+
+// DetectInjection 检查不可信文本中疑似提示词注入的片段，返回命中的片段，没有时返回 nil
+func DetectInjection(text string) []string {
+	var matches []string
+	for _, pattern := range injectionPatterns {
+		if m := pattern.FindString(text); m != "" {
+			matches = append(matches, strings.TrimSpace(m))
+		}
+	}
+	return matches
+}
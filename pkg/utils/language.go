@@ -0,0 +1,37 @@
+package utils
+
+import "unicode"
+
+// 简历语言
+const (
+	LanguageChinese   = "zh"
+	LanguageEnglish   = "en"
+	LanguageBilingual = "zh-en" // 中英双语
+)
+
+// DetectLanguage 按中文字符与英文单词的比例识别简历语言。一个英文单词按4个字母估算，
+// 中文简历中夹杂的技术术语不会影响结果；没有可识别的文字时按中文处理
+func DetectLanguage(text string) string {
+	han, latin := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin++
+		}
+	}
+	words := float64(latin) / 4
+	if han == 0 && words == 0 {
+		return LanguageChinese
+	}
+
+	share := float64(han) / (float64(han) + words)
+	switch {
+	case share >= 0.7:
+		return LanguageChinese
+	case share <= 0.2:
+		return LanguageEnglish
+	}
+	return LanguageBilingual
+}
@@ -0,0 +1,307 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// 版面分析参数
+const (
+	pdfBucketWidth   = 2.0  // 分栏检测时横向划分的宽度（pt）
+	pdfMinGutter     = 10.0 // 栏间空白的最小宽度（pt）
+	pdfGutterMaxHits = 0.1  // 栏间空白允许被跨栏行覆盖的比例
+)
+
+// pdfLine 同一基线上的文字
+type pdfLine struct {
+	y     float64
+	size  float64
+	texts []pdf.Text
+}
+
+// parsePDF 提取PDF文本，按坐标还原阅读顺序，双栏排版时先左栏后右栏
+func parsePDF(filePath string) (content string, err error) {
+	// 第三方库遇到损坏的文件时会panic，读取交叉引用表时也可能发生，需在打开前注册
+	defer func() {
+		if e := recover(); e != nil {
+			content, err = "", fmt.Errorf("failed to parse pdf: %v", e)
+		}
+	}()
+
+	// 自行打开文件，解析时panic也能关闭
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open pdf: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat pdf: %v", err)
+	}
+	r, err := pdf.NewReader(f, info.Size())
+	if err != nil {
+		return "", fmt.Errorf("failed to open pdf: %v", err)
+	}
+
+	var b strings.Builder
+	for i := 1; i <= r.NumPage(); i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		for _, line := range layoutPDFPage(page.Content().Text) {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	if strings.TrimSpace(b.String()) == "" {
+		return "", errors.New("no text found in pdf, it may be a scanned image")
+	}
+	return b.String(), nil
+}
+
+// layoutPDFPage 将一页的文字还原为按阅读顺序排列的文本行
+func layoutPDFPage(texts []pdf.Text) []string {
+	lines := groupPDFLines(texts)
+	split, ok := findPDFGutter(lines)
+	if !ok {
+		return renderPDFLines(lines)
+	}
+
+	// 跨栏的行（如姓名、标题）将页面分成若干块，每块内先输出左栏再输出右栏
+	var result []string
+	var leftLines, rightLines []pdfLine
+	flush := func() {
+		result = append(result, renderPDFLines(leftLines)...)
+		result = append(result, renderPDFLines(rightLines)...)
+		leftLines, rightLines = nil, nil
+	}
+	for _, line := range lines {
+		if spansPDFGutter(line, split) {
+			flush()
+			result = append(result, renderPDFLines([]pdfLine{line})...)
+			continue
+		}
+		l, r := line, line
+		l.texts, r.texts = nil, nil
+		for _, t := range line.texts {
+			if t.X < split {
+				l.texts = append(l.texts, t)
+			} else {
+				r.texts = append(r.texts, t)
+			}
+		}
+		if len(l.texts) > 0 {
+			leftLines = append(leftLines, l)
+		}
+		if len(r.texts) > 0 {
+			rightLines = append(rightLines, r)
+		}
+	}
+	flush()
+	return result
+}
+
+// groupPDFLines 按纵坐标将文字分组为行，从上到下排列
+func groupPDFLines(texts []pdf.Text) []pdfLine {
+	sorted := make([]pdf.Text, 0, len(texts))
+	for _, t := range texts {
+		if t.S != "" {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y > sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+
+	var lines []pdfLine
+	for _, t := range sorted {
+		size := math.Max(t.FontSize, 1)
+		if n := len(lines); n > 0 && math.Abs(lines[n-1].y-t.Y) <= size*0.5 {
+			lines[n-1].texts = append(lines[n-1].texts, t)
+			lines[n-1].size = math.Max(lines[n-1].size, size)
+			continue
+		}
+		lines = append(lines, pdfLine{y: t.Y, size: size, texts: []pdf.Text{t}})
+	}
+	for i := range lines {
+		sort.SliceStable(lines[i].texts, func(a, b int) bool {
+			return lines[i].texts[a].X < lines[i].texts[b].X
+		})
+	}
+	return lines
+}
+
+// findPDFGutter 查找页面中部贯穿大部分行的竖向空白，返回分栏位置
+func findPDFGutter(lines []pdfLine) (float64, bool) {
+	if len(lines) < 4 {
+		return 0, false
+	}
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, line := range lines {
+		for _, t := range line.texts {
+			minX = math.Min(minX, t.X)
+			maxX = math.Max(maxX, t.X+pdfTextWidth(t))
+		}
+	}
+	buckets := int((maxX-minX)/pdfBucketWidth) + 1
+	if buckets < 10 {
+		return 0, false
+	}
+
+	// 统计每个区间被多少行覆盖
+	hits := make([]int, buckets)
+	for _, line := range lines {
+		covered := make([]bool, buckets)
+		for _, t := range line.texts {
+			if strings.TrimSpace(t.S) == "" {
+				continue
+			}
+			from := int((t.X - minX) / pdfBucketWidth)
+			to := int((t.X + pdfTextWidth(t) - minX) / pdfBucketWidth)
+			for b := from; b <= to && b < buckets; b++ {
+				covered[b] = true
+			}
+		}
+		for b, c := range covered {
+			if c {
+				hits[b]++
+			}
+		}
+	}
+
+	// 在页面中间区域寻找最宽的空白
+	maxHits := int(float64(len(lines)) * pdfGutterMaxHits)
+	bestStart, bestLen, start := 0, 0, -1
+	for b := buckets / 4; b <= buckets*3/4; b++ {
+		if hits[b] <= maxHits {
+			if start < 0 {
+				start = b
+			}
+			if b-start+1 > bestLen {
+				bestStart, bestLen = start, b-start+1
+			}
+		} else {
+			start = -1
+		}
+	}
+	if float64(bestLen)*pdfBucketWidth < pdfMinGutter {
+		return 0, false
+	}
+	split := minX + (float64(bestStart)+float64(bestLen)/2)*pdfBucketWidth
+
+	// 两侧都要有足够多的行，避免把右对齐的日期等误判为分栏
+	leftRows, rightRows := 0, 0
+	for _, line := range lines {
+		if line.texts[0].X < split {
+			leftRows++
+		}
+		if last := line.texts[len(line.texts)-1]; last.X >= split {
+			rightRows++
+		}
+	}
+	if leftRows < len(lines)/3 || rightRows < len(lines)/3 {
+		return 0, false
+	}
+	return split, true
+}
+
+// spansPDFGutter 判断该行是否有文字跨过分栏位置
+func spansPDFGutter(line pdfLine, split float64) bool {
+	for _, t := range line.texts {
+		if t.X < split && t.X+pdfTextWidth(t) > split && strings.TrimSpace(t.S) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderPDFLines 拼接每行文字，段落间距较大时插入空行
+func renderPDFLines(lines []pdfLine) []string {
+	var result []string
+	for i, line := range lines {
+		text := renderPDFLine(line)
+		if text == "" {
+			continue
+		}
+		if i > 0 && lines[i-1].y-line.y > line.size*2 && len(result) > 0 && result[len(result)-1] != "" {
+			result = append(result, "")
+		}
+		result = append(result, text)
+	}
+	return result
+}
+
+// renderPDFLine 按横坐标拼接一行文字，英文单词之间的间隙补空格，中日韩文字之间不加空格
+func renderPDFLine(line pdfLine) string {
+	var b strings.Builder
+	var prev *pdf.Text
+	for i := range line.texts {
+		t := &line.texts[i]
+		if prev != nil {
+			// 加粗效果常通过重复绘制同一文字实现
+			if t.S == prev.S && math.Abs(t.X-prev.X) < 0.5 {
+				continue
+			}
+			gap := t.X - (prev.X + pdfTextWidth(*prev))
+			if gap > math.Max(t.FontSize, 1)*0.25 && prev.S != " " && t.S != " " &&
+				!isCJK(lastRune(prev.S)) && !isCJK(firstRune(t.S)) {
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString(t.S)
+		prev = t
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// pdfTextWidth 返回文字宽度，缺少字宽信息时按字号估算
+func pdfTextWidth(t pdf.Text) float64 {
+	if t.W > 0 {
+		return t.W
+	}
+	width := 0.0
+	for _, r := range t.S {
+		if isCJK(r) {
+			width += t.FontSize
+		} else {
+			width += t.FontSize * 0.5
+		}
+	}
+	return width
+}
+
+// isCJK 判断是否为中日韩文字或全角标点
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// firstRune 返回字符串的第一个字符
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// lastRune 返回字符串的最后一个字符
+func lastRune(s string) rune {
+	r := []rune(s)
+	if len(r) == 0 {
+		return 0
+	}
+	return r[len(r)-1]
+}
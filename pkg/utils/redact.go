@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 可脱敏的个人信息类型
+const (
+	PIIPhone   = "phone"   // 手机号及国际电话
+	PIIEmail   = "email"   // 邮箱
+	PIIIDCard  = "id_card" // 居民身份证号
+	PIIAddress = "address" // 住址
+	PIIName    = "name"    // 姓名
+)
+
+// PIITypes 全部可脱敏的个人信息类型，按替换顺序排列：身份证号先于电话，避免号码中的片段被识别为手机号
+var PIITypes = []string{PIIIDCard, PIIEmail, PIIPhone, PIIAddress, PIIName}
+
+// 占位符前缀
+var piiPlaceholderPrefix = map[string]string{
+	PIIPhone:   "PHONE",
+	PIIEmail:   "EMAIL",
+	PIIIDCard:  "ID",
+	PIIAddress: "ADDRESS",
+	PIIName:    "NAME",
+}
+
+var (
+	idCardPattern = regexp.MustCompile(`\b[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`)
+	// addressLabelPattern “地址：”“现居地：”“Address:”等标注的值，到行尾或分隔符为止
+	addressLabelPattern = regexp.MustCompile(`(?i)(?:(?:家庭|通讯|联系|居住|现居住?)?(?:地址|住址)|现居地?|居住地|address)\s*[:：]\s*([^\n|｜]+)`)
+	// streetAddressPattern 未标注的街道门牌地址，如“海淀区中关村大街1号院3号楼”
+	streetAddressPattern = regexp.MustCompile(`[\p{Han}\d]{0,20}(?:路|街|大道|巷|弄|胡同)[\dA-Za-z-]+号(?:[\p{Han}\dA-Za-z-]{0,10}?(?:院|室|楼|栋|座|单元|层))*`)
+	placeholderPattern   = regexp.MustCompile(`\[(?:PHONE|EMAIL|ID|ADDRESS|NAME)_\d+\]`)
+)
+
+// idCardWeights 身份证号前17位的加权因子
+var idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// maxPlaceholderLen 占位符的最大长度，流式恢复时用于判断缓冲的内容是否可能是未完整的占位符
+const maxPlaceholderLen = len("[ADDRESS_9999]")
+
+// Redactor 将文本中的个人信息替换为 [PHONE_1] 这类占位符，并在回复中恢复原值；
+// 同一个值在一次会话中始终使用同一个占位符
+type Redactor struct {
+	types        map[string]bool
+	placeholders map[string]string // 原值到占位符
+	values       map[string]string // 占位符到原值
+	counts       map[string]int
+}
+
+// NewRedactor 创建脱敏器，types 为空时脱敏全部类型，未知类型忽略
+func NewRedactor(types []string) *Redactor {
+	r := &Redactor{
+		types:        make(map[string]bool),
+		placeholders: make(map[string]string),
+		values:       make(map[string]string),
+		counts:       make(map[string]int),
+	}
+	if len(types) == 0 {
+		types = PIITypes
+	}
+	for _, t := range types {
+		if _, ok := piiPlaceholderPrefix[t]; ok {
+			r.types[t] = true
+		}
+	}
+	return r
+}
+
+// Redact 脱敏一段用户提供的文本
+func (r *Redactor) Redact(text string) string {
+	return r.RedactAll([]string{text}, nil)[0]
+}
+
+// RedactAll 脱敏多段文本，如同一次请求中各条消息拆出的片段；untrusted 标记哪些片段是用户提供的内容，
+// 姓名只在这些片段中识别和替换，提示词中被误判为姓名的词（如“前端开发”）不会被替换；
+// untrusted 为 nil 时全部视为用户提供的内容。其他类型按格式识别，在所有片段中替换
+func (r *Redactor) RedactAll(texts []string, untrusted []bool) []string {
+	isUntrusted := func(i int) bool { return untrusted == nil || untrusted[i] }
+
+	var names []string
+	if r.types[PIIName] {
+		for i, text := range texts {
+			if isUntrusted(i) {
+				names = append(names, detectNames(text)...)
+			}
+		}
+		// 长的姓名先替换，避免“张三丰”被替换成“[NAME_1]丰”
+		sort.SliceStable(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	}
+
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		for _, t := range PIITypes {
+			if !r.types[t] {
+				continue
+			}
+			if t == PIIName {
+				if !isUntrusted(i) {
+					continue
+				}
+				for _, name := range names {
+					text = strings.ReplaceAll(text, name, r.placeholder(PIIName, name))
+				}
+				continue
+			}
+			text = r.replace(t, text)
+		}
+		out[i] = text
+	}
+	return out
+}
+
+// replace 替换一类个人信息
+func (r *Redactor) replace(piiType, text string) string {
+	switch piiType {
+	case PIIIDCard:
+		return idCardPattern.ReplaceAllStringFunc(text, func(m string) string {
+			if !validIDCard(m) {
+				return m
+			}
+			return r.placeholder(piiType, strings.ToUpper(m))
+		})
+	case PIIEmail:
+		return emailPattern.ReplaceAllStringFunc(text, func(m string) string {
+			return r.placeholder(piiType, m)
+		})
+	case PIIPhone:
+		text = phonePattern.ReplaceAllStringFunc(text, func(m string) string {
+			return r.placeholder(piiType, m)
+		})
+		return intlPhonePattern.ReplaceAllStringFunc(text, func(m string) string {
+			if !isPhoneNumber(m) {
+				return m
+			}
+			return r.placeholder(piiType, m)
+		})
+	case PIIAddress:
+		text = replaceSubmatch(addressLabelPattern, text, func(m string) string {
+			value := strings.TrimSpace(m)
+			if value == "" || placeholderPattern.FindString(value) == value {
+				return m
+			}
+			return strings.Replace(m, value, r.placeholder(piiType, value), 1)
+		})
+		return streetAddressPattern.ReplaceAllStringFunc(text, func(m string) string {
+			return r.placeholder(piiType, m)
+		})
+	}
+	return text
+}
+
+// placeholder 返回原值对应的占位符，首次出现时分配新的编号
+func (r *Redactor) placeholder(piiType, value string) string {
+	if p, ok := r.placeholders[value]; ok {
+		return p
+	}
+	r.counts[piiType]++
+	p := fmt.Sprintf("[%s_%d]", piiPlaceholderPrefix[piiType], r.counts[piiType])
+	r.placeholders[value] = p
+	// 值中可能包含先替换的其他个人信息，如地址所在行的电话，保存时先按原值恢复
+	r.values[p] = r.restore(value, nil)
+	return p
+}
+
+// Restore 将文本中的占位符恢复为原值，未知的占位符保持不变；
+// 回复通常是JSON，位于字符串字面量内的占位符按JSON转义恢复，避免原值中的引号和反斜杠破坏JSON
+func (r *Redactor) Restore(text string) string {
+	return r.restore(text, &jsonScanner{})
+}
+
+// restore 恢复占位符，scanner 为空时按原值恢复，否则记录扫描过的文本是否处于JSON字符串内
+func (r *Redactor) restore(text string, scanner *jsonScanner) string {
+	if len(r.values) == 0 {
+		if scanner != nil {
+			scanner.scan(text)
+		}
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringIndex(text, -1) {
+		b.WriteString(text[last:loc[0]])
+		if scanner != nil {
+			scanner.scan(text[last:loc[0]])
+		}
+		p := text[loc[0]:loc[1]]
+		v, ok := r.values[p]
+		switch {
+		case !ok:
+			b.WriteString(p)
+		case scanner != nil && scanner.inString:
+			b.WriteString(jsonEscape(v))
+		default:
+			b.WriteString(v)
+		}
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	if scanner != nil {
+		scanner.scan(text[last:])
+	}
+	return b.String()
+}
+
+// jsonScanner 跟踪文本当前是否处于JSON字符串字面量内，可跨多个流式片段连续扫描
+type jsonScanner struct {
+	inString bool
+	escaped  bool
+}
+
+// scan 扫描一段文本并更新状态
+func (s *jsonScanner) scan(text string) {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString && text[i] == '\\':
+			s.escaped = true
+		case text[i] == '"':
+			s.inString = !s.inString
+		}
+	}
+}
+
+// jsonEscape 返回字符串在JSON字符串字面量中的写法，不含两侧引号
+func jsonEscape(v string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	out := strings.TrimSuffix(b.String(), "\n")
+	return out[1 : len(out)-1]
+}
+
+// NewStreamRestorer 创建流式回复的占位符恢复器
+func (r *Redactor) NewStreamRestorer() *StreamRestorer {
+	return &StreamRestorer{redactor: r}
+}
+
+// StreamRestorer 恢复流式回复中的占位符，占位符可能被拆分到多个片段中，未完整的部分暂存到下一个片段；
+// 同时跨片段跟踪JSON字符串状态，与 Restore 一样转义字符串内恢复的原值
+type StreamRestorer struct {
+	redactor *Redactor
+	pending  string
+	scanner  jsonScanner
+}
+
+// Write 输入一个片段，返回可以输出的已恢复内容
+func (s *StreamRestorer) Write(delta string) string {
+	text := s.pending + delta
+	s.pending = ""
+	// 最后一个“[”之后没有“]”且长度不超过占位符时可能是未完整的占位符
+	if i := strings.LastIndex(text, "["); i >= 0 && !strings.Contains(text[i:], "]") && len(text)-i < maxPlaceholderLen {
+		s.pending = text[i:]
+		text = text[:i]
+	}
+	return s.redactor.restore(text, &s.scanner)
+}
+
+// Flush 返回暂存的剩余内容，在流结束时调用
+func (s *StreamRestorer) Flush() string {
+	text := s.pending
+	s.pending = ""
+	return s.redactor.restore(text, &s.scanner)
+}
+
+// detectNames 识别文本中的姓名：“姓名：”“Name:”标注的值，以及包含联系方式的文本开头几行中的姓名
+func detectNames(text string) []string {
+	var names []string
+	for _, line := range strings.Split(text, "\n") {
+		if m := nameLabelPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if name := nameFromLine(m[1]); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	// 开头几行的识别容易误判，只用于像简历的文本
+	if len(names) == 0 && (phonePattern.MatchString(text) || emailPattern.MatchString(text)) {
+		if name := extractName(text); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// replaceSubmatch 替换正则第一个分组匹配的内容，分组之外的部分保持不变
+func replaceSubmatch(re *regexp.Regexp, text string, repl func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:loc[2]])
+		b.WriteString(repl(text[loc[2]:loc[3]]))
+		last = loc[3]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// validIDCard 校验18位居民身份证号的校验码
+func validIDCard(id string) bool {
+	sum := 0
+	for i, w := range idCardWeights {
+		sum += int(id[i]-'0') * w
+	}
+	check := "10X98765432"[sum%11]
+	last := id[17]
+	if last == 'x' {
+		last = 'X'
+	}
+	return last == check
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactorRoundTrip(t *testing.T) {
+	text := "姓名：张三\n电话：138-1234-5678\n邮箱：zhangsan@example.com\n身份证：11010519491231002X\n地址：北京市海淀区中关村大街1号"
+
+	r := NewRedactor(nil)
+	redacted := r.Redact(text)
+	for _, value := range []string{"张三", "138-1234-5678", "zhangsan@example.com", "11010519491231002X", "中关村大街1号"} {
+		if strings.Contains(redacted, value) {
+			t.Errorf("Redact() left %q in %q", value, redacted)
+		}
+	}
+	if got := r.Restore(redacted); got != text {
+		t.Errorf("Restore() = %q, want %q", got, text)
+	}
+	// 同一个值再次出现时使用同一个占位符
+	if again := r.Redact("手机 138-1234-5678"); !strings.Contains(again, "[PHONE_1]") {
+		t.Errorf("Redact() of a known phone = %q, want [PHONE_1]", again)
+	}
+}
+
+func TestRedactorIDCardChecksum(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		redact bool
+	}{
+		{name: "valid", id: "11010519491231002X", redact: true},
+		{name: "lowercase check digit", id: "11010519491231002x", redact: true},
+		{name: "wrong check digit", id: "110105194912310021", redact: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRedactor([]string{PIIIDCard}).Redact("身份证号 " + tt.id)
+			if redacted := !strings.Contains(got, tt.id); redacted != tt.redact {
+				t.Errorf("Redact() = %q, want redacted %v", got, tt.redact)
+			}
+		})
+	}
+}
+
+func TestRedactorRestoreJSON(t *testing.T) {
+	r := NewRedactor([]string{PIIAddress})
+	redacted := r.Redact(`地址：北京市"望京"路\1号`)
+	placeholder := placeholderPattern.FindString(redacted)
+	if placeholder == "" {
+		t.Fatalf("Redact() = %q, want an address placeholder", redacted)
+	}
+
+	// 字符串内的原值按JSON转义，字符串外的原值保持原样
+	restored := r.Restore(`{"location": "` + placeholder + `"}`)
+	var out struct {
+		Location string `json:"location"`
+	}
+	if err := json.Unmarshal([]byte(restored), &out); err != nil {
+		t.Fatalf("Restore() = %q is not valid JSON: %v", restored, err)
+	}
+	if want := `北京市"望京"路\1号`; out.Location != want {
+		t.Errorf("location = %q, want %q", out.Location, want)
+	}
+	if got, want := r.Restore("住址 "+placeholder), `住址 北京市"望京"路\1号`; got != want {
+		t.Errorf("Restore() = %q, want %q", got, want)
+	}
+}
+
+func TestStreamRestorer(t *testing.T) {
+	r := NewRedactor([]string{PIIEmail})
+	placeholder := r.Redact("zhangsan@example.com")
+
+	tests := []struct {
+		name   string
+		deltas []string
+		want   string
+	}{
+		{name: "whole placeholder", deltas: []string{"邮箱 " + placeholder + " 已验证"}, want: "邮箱 zhangsan@example.com 已验证"},
+		{name: "split placeholder", deltas: []string{"邮箱 [EMA", "IL_", "1] 已验证"}, want: "邮箱 zhangsan@example.com 已验证"},
+		{name: "placeholder at end", deltas: []string{"邮箱 [EMAIL", "_1]"}, want: "邮箱 zhangsan@example.com"},
+		{name: "unknown placeholder", deltas: []string{"[EMAIL_", "9]"}, want: "[EMAIL_9]"},
+		{name: "plain bracket", deltas: []string{"数组 [1, 2", "]"}, want: "数组 [1, 2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restorer := r.NewStreamRestorer()
+			var b strings.Builder
+			for _, delta := range tt.deltas {
+				b.WriteString(restorer.Write(delta))
+			}
+			b.WriteString(restorer.Flush())
+			if got := b.String(); got != tt.want {
+				t.Errorf("restored stream = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamRestorerJSONAcrossDeltas(t *testing.T) {
+	r := NewRedactor([]string{PIIAddress})
+	placeholder := placeholderPattern.FindString(r.Redact(`地址：上海"浦东"`))
+
+	// 字符串的开始引号和占位符在不同片段中
+	restorer := r.NewStreamRestorer()
+	got := restorer.Write(`{"location": "`) + restorer.Write(placeholder[:4]) + restorer.Write(placeholder[4:]+`"}`) + restorer.Flush()
+	if want := `{"location": "上海\"浦东\""}`; got != want {
+		t.Errorf("restored stream = %q, want %q", got, want)
+	}
+}
+
+func TestRedactAllNamesOnlyInUntrusted(t *testing.T) {
+	// 简历首行的“前端开发”会被识别为姓名，只在用户提供的片段中替换，提示词中的同一个词保持不变
+	texts := []string{
+		"请评估候选人与前端开发岗位的匹配程度",
+		"<resume>\n前端开发\n电话：13812345678\n</resume>",
+	}
+	got := NewRedactor([]string{PIIName, PIIPhone}).RedactAll(texts, []bool{false, true})
+	if got[0] != texts[0] {
+		t.Errorf("trusted text = %q, want unchanged", got[0])
+	}
+	if want := "<resume>\n[NAME_1]\n电话：[PHONE_1]\n</resume>"; got[1] != want {
+		t.Errorf("untrusted text = %q, want %q", got[1], want)
+	}
+}
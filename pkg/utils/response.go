@@ -27,3 +27,12 @@ func ErrorResponse(ctx *gin.Context, code int, message string) {
 		Message: message,
 	})
 }
+
+// ErrorResponseWithData 携带数据的错误响应
+func ErrorResponseWithData(ctx *gin.Context, code int, message string, data interface{}) {
+	ctx.JSON(code, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+}
@@ -1,29 +1,97 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
-	"github.com/unidoc/unioffice/document"
+	"github.com/gabriel-vasile/mimetype"
 )
 
 var (
 	// 正则表达式模式
-	phonePattern = regexp.MustCompile(`1[3-9]\d{9}`)
-	emailPattern = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+	phonePattern     = regexp.MustCompile(`(?:\+86[\s-]?)?1[3-9]\d[\s-]?\d{4}[\s-]?\d{4}`)
+	intlPhonePattern = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{1,4}\)[\s.-]?)?\d{2,5}(?:[\s.-]\d{2,5}){1,4}|\+\d{8,15}`)
+	datePattern      = regexp.MustCompile(`(?:19|20)\d{2}\s*[./-]\s*(?:(?:19|20)\d{2}|\d{1,2})\b`)
+	emailPattern     = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+	nameLabelPattern = regexp.MustCompile(`(?i)^(?:姓\s*名|name)\s*[:：]\s*(.+)$`)
+	chineseName      = regexp.MustCompile(`^(?:\p{Han}{2,4}|\p{Han}{1,8}·\p{Han}{1,8})$`)
+	bilingualName    = regexp.MustCompile(`^(\p{Han}{2,4})\s*[(（]?[A-Z][a-z]+`)
+	englishName      = regexp.MustCompile(`^[A-Z][A-Za-z'’.-]*(?:\s+[A-Z][A-Za-z'’.-]*){1,3}$`)
+
+	// 关键词，英文为小写
+	educationKeywords  = []string{"教育经历", "教育背景", "学历", "学校", "大学", "学院", "education", "academic", "university", "college"}
+	experienceKeywords = []string{"工作经历", "工作经验", "实习经历", "项目经验", "工作背景", "experience", "employment", "work history", "internship", "projects"}
+	skillKeywords      = []string{"专业技能", "技能", "技术栈", "skills", "technologies"}
+
+	// nameStopWords 首行常见但不是姓名的英文单词，如 "Resume"、"Software Engineer"
+	nameStopWords = []string{"resume", "curriculum", "vitae", "cv", "engineer", "developer", "manager", "designer",
+		"analyst", "intern", "consultant", "scientist", "architect", "specialist", "director", "officer", "lead"}
+)
 
-	// 关键词
-	educationKeywords  = []string{"教育经历", "教育背景", "学历", "学校", "大学", "学院"}
-	experienceKeywords = []string{"工作经历", "工作经验", "实习经历", "项目经验", "工作背景"}
+// 简历文件的内容类型
+const (
+	ContentTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	ContentTypePDF  = "application/pdf"
+	ContentTypeDOC  = "application/msword"
+	ContentTypeOLE  = "application/x-ole-storage" // 未能识别出具体应用的复合文档，按 .doc 尝试解析
+	ContentTypeText = "text/plain"
+	ContentTypeHTML = "text/html"
+	// ContentTypeMarkdown 无法从内容中识别，仅用于粘贴的文本；.md 文件识别为 text/plain 后按扩展名处理
+	ContentTypeMarkdown = "text/markdown"
+)
+
+// ErrUnsupportedFormat 没有对应解析器的文件类型
+var ErrUnsupportedFormat = errors.New("unsupported file type")
+
+// Parser 将某一格式的简历文件解析为结构化的中间文档
+type Parser interface {
+	Parse(filePath string) (*Document, error)
+}
+
+// ParserFunc 函数形式的解析器
+type ParserFunc func(filePath string) (*Document, error)
+
+// Parse 实现Parser接口
+func (f ParserFunc) Parse(filePath string) (*Document, error) {
+	return f(filePath)
+}
+
+// TextParser 将只能提取纯文本的解析函数包装为解析器，按行构建文档
+func TextParser(parse func(filePath string) (string, error)) Parser {
+	return ParserFunc(func(filePath string) (*Document, error) {
+		text, err := parse(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return TextDocument(text), nil
+	})
+}
+
+// 按内容类型注册的解析器
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]Parser{
+		ContentTypeDOCX: ParserFunc(parseDOCX),
+		ContentTypePDF:  TextParser(parsePDF),
+		ContentTypeDOC:  TextParser(parseDOC),
+		ContentTypeOLE:  TextParser(parseDOC),
+		ContentTypeText: ParserFunc(parseText),
+		ContentTypeHTML: ParserFunc(parseHTML),
+	}
 )
 
 // ResumeParser 简历解析器
 type ResumeParser struct {
-	FilePath string
+	FilePath    string
+	ContentType string // 解析后识别出的内容类型
 }
 
 // NewResumeParser 创建新的简历解析器
@@ -33,36 +101,66 @@ func NewResumeParser(filePath string) *ResumeParser {
 	}
 }
 
-// Parse 解析简历文件
-func (p *ResumeParser) Parse() (string, error) {
+// Parse 解析简历文件，按文件内容识别的类型选择解析器
+func (p *ResumeParser) Parse() (*Document, error) {
 	// 检查文件是否存在
 	if _, err := os.Stat(p.FilePath); os.IsNotExist(err) {
-		return "", fmt.Errorf("file not found: %s", p.FilePath)
+		return nil, fmt.Errorf("file not found: %s", p.FilePath)
 	}
 
-	// 检查文件类型
-	ext := strings.ToLower(filepath.Ext(p.FilePath))
-	if ext != ".docx" && ext != ".doc" {
-		return "", fmt.Errorf("unsupported file type: %s", ext)
+	// 识别文件类型
+	contentType, err := DetectContentType(p.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	p.ContentType = contentType
+
+	parser := lookupParser(contentType)
+	if parser == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+	doc, err := parser.Parse(p.FilePath)
+	if err != nil {
+		return nil, err
 	}
+	doc.InferHeadings()
+	return doc, nil
+}
+
+// RegisterParser 注册某一内容类型的解析器，已注册的类型会被覆盖
+func RegisterParser(contentType string, parser Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[contentType] = parser
+}
 
-	// 打开文档
-	doc, err := document.Open(p.FilePath)
+// DetectContentType 根据文件内容识别内容类型，返回不带参数的MIME类型
+func DetectContentType(filePath string) (string, error) {
+	mtype, err := mimetype.DetectFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open document: %v", err)
+		return "", fmt.Errorf("failed to detect file type: %v", err)
 	}
-	defer doc.Close()
+	return baseMediaType(mtype.String()), nil
+}
 
-	// 提取文本内容
-	var content strings.Builder
-	for _, para := range doc.Paragraphs() {
-		for _, run := range para.Runs() {
-			content.WriteString(run.Text())
+// lookupParser 查找内容类型对应的解析器，未注册时依次尝试其父类型，如 text/html 之于 text/plain
+func lookupParser(contentType string) Parser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	for mtype := mimetype.Lookup(contentType); mtype != nil; mtype = mtype.Parent() {
+		if parser, ok := parsers[baseMediaType(mtype.String())]; ok {
+			return parser
 		}
-		content.WriteString("\n")
 	}
+	return parsers[contentType]
+}
 
-	return content.String(), nil
+// baseMediaType 去掉MIME类型中的参数，如 text/plain; charset=utf-8 → text/plain
+func baseMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
 }
 
 // SaveUploadedFile 保存上传的文件
@@ -90,9 +188,11 @@ func SaveUploadedFile(src io.Reader, filename string, uploadDir string) (string,
 	return filePath, nil
 }
 
-// ExtractResumeInfo 从简历内容中提取关键信息
-func ExtractResumeInfo(content string) map[string]string {
+// ExtractResumeInfo 从简历文档中提取关键信息，教育和工作经历优先按段落标题定位，支持中英文简历
+func ExtractResumeInfo(doc *Document) map[string]string {
 	info := make(map[string]string)
+	content := doc.Text()
+	info["language"] = DetectLanguage(content)
 
 	// 提取姓名
 	if name := extractName(content); name != "" {
@@ -110,35 +210,141 @@ func ExtractResumeInfo(content string) map[string]string {
 	}
 
 	// 提取教育经历
-	if education := extractEducation(content); education != "" {
+	education := sectionText(doc, educationKeywords)
+	if education == "" {
+		education = extractEducation(content)
+	}
+	if education != "" {
 		info["education"] = education
 	}
 
 	// 提取工作经历
-	if experience := extractExperience(content); experience != "" {
+	experience := sectionText(doc, experienceKeywords)
+	if experience == "" {
+		experience = extractExperience(content)
+	}
+	if experience != "" {
 		info["experience"] = experience
 	}
 
+	// 提取技能
+	if skills := sectionText(doc, skillKeywords); skills != "" {
+		info["skills"] = skills
+	}
+
 	return info
 }
 
-// 辅助函数：提取姓名
+// 辅助函数：返回标题包含任一关键词的段落文本，多个段落依次拼接
+func sectionText(doc *Document, keywords []string) string {
+	var b strings.Builder
+	for _, section := range doc.Sections() {
+		if section.Heading != "" && containsKeyword(section.Heading, keywords) {
+			b.WriteString(section.Text())
+		}
+	}
+	return b.String()
+}
+
+// 辅助函数：判断文本是否包含任一关键词，英文不区分大小写
+func containsKeyword(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// 辅助函数：提取姓名，优先使用“姓名：”“Name:”标注的值，否则取文档开头几行中第一个像姓名的行
 func extractName(content string) string {
-	// 假设姓名在文档开头，且长度在2-4个字符之间
 	lines := strings.Split(content, "\n")
-	if len(lines) > 0 {
-		firstLine := strings.TrimSpace(lines[0])
-		if len(firstLine) >= 2 && len(firstLine) <= 4 {
-			return firstLine
+	for _, line := range lines {
+		if m := nameLabelPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if name := nameFromLine(m[1]); name != "" {
+				return name
+			}
+		}
+	}
+
+	checked := 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if checked++; checked > 5 {
+			break
+		}
+		if name := nameFromLine(line); name != "" {
+			return name
 		}
 	}
 	return ""
 }
 
-// 辅助函数：提取电话
+// 辅助函数：判断一行的第一个字段是否为中文或英文姓名，中英双语姓名返回中文部分
+func nameFromLine(line string) string {
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return r == '\t' || r == '|' || r == '｜'
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	field := strings.TrimSpace(fields[0])
+	if i := strings.Index(field, "  "); i > 0 {
+		field = field[:i]
+	}
+	if field == "" || isSectionTitle(field) || strings.Contains(field, "简历") {
+		return ""
+	}
+
+	if chineseName.MatchString(field) {
+		return field
+	}
+	if m := bilingualName.FindStringSubmatch(field); m != nil {
+		return m[1]
+	}
+	if englishName.MatchString(field) && utf8.RuneCountInString(field) <= 40 {
+		for _, word := range strings.Fields(strings.ToLower(field)) {
+			for _, stop := range nameStopWords {
+				if word == stop {
+					return ""
+				}
+			}
+		}
+		return field
+	}
+	return ""
+}
+
+// 辅助函数：提取电话，优先识别中国大陆手机号，其次为带国家码或分隔符的国际号码
 func extractPhone(content string) string {
-	matches := phonePattern.FindString(content)
-	return matches
+	if phone := phonePattern.FindString(content); phone != "" {
+		return phone
+	}
+	for _, candidate := range intlPhonePattern.FindAllString(content, -1) {
+		if isPhoneNumber(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// 辅助函数：判断候选号码是否为电话：带国家码时8-15位数字，否则10-15位，排除“2015.09-2019.06”这类日期
+func isPhoneNumber(candidate string) bool {
+	digits := 0
+	for _, r := range candidate {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	minDigits := 10
+	if strings.HasPrefix(candidate, "+") {
+		minDigits = 8
+	}
+	return digits >= minDigits && digits <= 15 && !datePattern.MatchString(candidate)
 }
 
 // 辅助函数：提取邮箱
@@ -161,21 +367,16 @@ func extractEducation(content string) string {
 
 		// 检查是否进入教育经历部分
 		if !inEducationSection {
-			for _, keyword := range educationKeywords {
-				if strings.Contains(line, keyword) {
-					inEducationSection = true
-					education.WriteString(line + "\n")
-					break
-				}
+			if containsKeyword(line, educationKeywords) {
+				inEducationSection = true
+				education.WriteString(line + "\n")
 			}
 			continue
 		}
 
 		// 检查是否离开教育经历部分
-		for _, keyword := range experienceKeywords {
-			if strings.Contains(line, keyword) {
-				return education.String()
-			}
+		if containsKeyword(line, experienceKeywords) {
+			return education.String()
 		}
 
 		// 收集教育经历内容
@@ -199,21 +400,16 @@ func extractExperience(content string) string {
 
 		// 检查是否进入工作经历部分
 		if !inExperienceSection {
-			for _, keyword := range experienceKeywords {
-				if strings.Contains(line, keyword) {
-					inExperienceSection = true
-					experience.WriteString(line + "\n")
-					break
-				}
+			if containsKeyword(line, experienceKeywords) {
+				inExperienceSection = true
+				experience.WriteString(line + "\n")
 			}
 			continue
 		}
 
 		// 检查是否离开工作经历部分
-		for _, keyword := range educationKeywords {
-			if strings.Contains(line, keyword) {
-				return experience.String()
-			}
+		if containsKeyword(line, educationKeywords) {
+			return experience.String()
 		}
 
 		// 收集工作经历内容
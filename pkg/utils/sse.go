@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// SSE事件名称
+const (
+	SSEEventDelta  = "delta"
+	SSEEventDone   = "done"
+	SSEEventError  = "error"
+	SSEEventStatus = "status" // 后台处理状态变化
+)
+
+// 上下文中标记SSE响应的键
+const sseContextKey = "sse"
+
+// SSEStart 设置SSE响应头，返回推送增量文本的回调；响应头在第一次推送时才真正发出
+func SSEStart(ctx *gin.Context) func(delta string) error {
+	ctx.Set(sseContextKey, true)
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// 关闭Nginx等反向代理的缓冲
+	ctx.Header("X-Accel-Buffering", "no")
+
+	return func(delta string) error {
+		ctx.SSEvent(SSEEventDelta, gin.H{"content": delta})
+		ctx.Writer.Flush()
+		// 客户端断开后停止读取上游
+		return ctx.Request.Context().Err()
+	}
+}
+
+// IsSSE 判断当前请求是否为SSE响应
+func IsSSE(ctx *gin.Context) bool {
+	return ctx.GetBool(sseContextKey)
+}
+
+// SSEDone 推送最终结果
+func SSEDone(ctx *gin.Context, data interface{}) {
+	ctx.SSEvent(SSEEventDone, Response{
+		Code:    200,
+		Message: "success",
+		Data:    data,
+	})
+	ctx.Writer.Flush()
+}
+
+// SSEError 推送错误；尚未推送任何内容时改为返回普通JSON错误，以便携带正确的状态码
+func SSEError(ctx *gin.Context, code int, message string, data interface{}) {
+	if !ctx.Writer.Written() {
+		ctx.Writer.Header().Del("Content-Type")
+		ErrorResponseWithData(ctx, code, message, data)
+		return
+	}
+
+	ctx.SSEvent(SSEEventError, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+	ctx.Writer.Flush()
+}
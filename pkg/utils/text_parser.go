@@ -0,0 +1,385 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var (
+	// Markdown 块级语法
+	mdFencePattern    = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeadingPattern  = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	mdSetextPattern   = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	mdRulePattern     = regexp.MustCompile(`^\s*([-_*])(\s*([-_*]))*\s*$`)
+	mdTableSepPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?\s*$`)
+	mdQuotePattern    = regexp.MustCompile(`^\s*(>\s?)+`)
+	mdBulletPattern   = regexp.MustCompile(`^(\s*)[-*+]\s+(\[[ xX]\]\s+)?`)
+	mdOrderedPattern  = regexp.MustCompile(`^(\s*)\d{1,3}[.)]\s+`)
+	mdImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(\s*([^)\s]+)[^)]*\)`)
+	mdAutoLinkPattern = regexp.MustCompile(`<((?:https?://|mailto:)[^>\s]+|[^>\s@]+@[^>\s]+)>`)
+	mdTagPattern      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdCodePattern     = regexp.MustCompile("`([^`]+)`")
+	mdStrongPattern   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdEmphasisPattern = regexp.MustCompile(`\*([^*\s][^*]*?)\*`)
+	mdStrikePattern   = regexp.MustCompile(`~~(.+?)~~`)
+	mdEscapePattern   = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!|>~])")
+	htmlSpacePattern  = regexp.MustCompile(`\s+`)
+	htmlDetectPattern = regexp.MustCompile(`(?i)<(html|body|div|p|h[1-6]|ul|ol|li|table|br|span)[\s/>]`)
+	mdDetectPattern   = regexp.MustCompile(`(?m)^ {0,3}#{1,6}\s+\S|\[[^\]]+\]\([^)]+\)|\*\*[^*]+\*\*|^\s*[-*+]\s+\S`)
+)
+
+// parseText 解析纯文本或Markdown简历，Markdown按扩展名识别
+func parseText(filePath string) (*Document, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	text := decodeText(data)
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".md", ".markdown":
+		return markdownDocument(text), nil
+	}
+	return TextDocument(text), nil
+}
+
+// parseHTML 解析HTML简历
+func parseHTML(filePath string) (*Document, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return htmlDocument(decodeText(data))
+}
+
+// ParseText 按内容类型解析粘贴的文本，得到与文件解析结果一致的文档
+func ParseText(text, contentType string) (*Document, error) {
+	var doc *Document
+	switch contentType {
+	case ContentTypeText:
+		doc = TextDocument(text)
+	case ContentTypeMarkdown:
+		doc = markdownDocument(text)
+	case ContentTypeHTML:
+		var err error
+		if doc, err = htmlDocument(text); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+	doc.InferHeadings()
+	return doc, nil
+}
+
+// DetectTextFormat 猜测粘贴文本的格式，返回 ContentTypeHTML、ContentTypeMarkdown 或 ContentTypeText
+func DetectTextFormat(text string) string {
+	if htmlDetectPattern.MatchString(text) {
+		return ContentTypeHTML
+	}
+	if mdDetectPattern.MatchString(text) {
+		return ContentTypeMarkdown
+	}
+	return ContentTypeText
+}
+
+// decodeText 将文件内容解码为UTF-8：识别BOM，非UTF-8内容按GB18030解码
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err == nil {
+			return string(decoded)
+		}
+	case !utf8.Valid(data):
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err == nil {
+			return string(decoded)
+		}
+	}
+	return string(data)
+}
+
+// markdownDocument 将Markdown转换为文档：保留标题级别、列表和表格，去掉行内标记
+func markdownDocument(text string) *Document {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	// 跳过 YAML front matter
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				lines = lines[i+1:]
+				break
+			}
+		}
+	}
+
+	doc := &Document{}
+	var rows [][]string
+	flushTable := func() {
+		if len(rows) > 0 {
+			doc.Blocks = append(doc.Blocks, Block{Type: BlockTable, Rows: rows})
+			rows = nil
+		}
+	}
+	add := func(block Block) {
+		doc.Blocks = append(doc.Blocks, block)
+	}
+
+	inCode := false
+	for i, line := range lines {
+		if mdFencePattern.MatchString(line) {
+			flushTable()
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			if strings.TrimSpace(line) != "" {
+				add(Block{Type: BlockParagraph, Text: strings.TrimRight(line, " \t")})
+			}
+			continue
+		}
+
+		// 表格：连续的竖线分隔行合并为一个表格块
+		if mdTableSepPattern.MatchString(line) {
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "|") {
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			for c, cell := range cells {
+				cells[c] = strings.TrimSpace(markdownInline(cell))
+			}
+			rows = append(rows, cells)
+			continue
+		}
+		flushTable()
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := mdHeadingPattern.FindStringSubmatch(line); m != nil {
+			add(Block{Type: BlockHeading, Level: len(m[1]), Text: markdownInline(m[2])})
+			continue
+		}
+		// Setext 标题：紧跟在段落下一行的 === 或 --- 将该段落变为标题
+		if m := mdSetextPattern.FindStringSubmatch(line); m != nil && i > 0 && strings.TrimSpace(lines[i-1]) != "" &&
+			len(doc.Blocks) > 0 && doc.Blocks[len(doc.Blocks)-1].Type == BlockParagraph {
+			last := &doc.Blocks[len(doc.Blocks)-1]
+			last.Type, last.Level = BlockHeading, 1
+			if m[1][0] == '-' {
+				last.Level = 2
+			}
+			continue
+		}
+		// 分隔线
+		if mdRulePattern.MatchString(line) && len(strings.TrimSpace(line)) >= 3 || mdSetextPattern.MatchString(line) {
+			continue
+		}
+
+		line = mdQuotePattern.ReplaceAllString(line, "")
+		if m := mdBulletPattern.FindStringSubmatch(line); m != nil {
+			add(Block{Type: BlockListItem, Level: len(m[1]) / 2, Text: markdownInline(strings.TrimSpace(line[len(m[0]):]))})
+			continue
+		}
+		if m := mdOrderedPattern.FindStringSubmatch(line); m != nil {
+			add(Block{Type: BlockListItem, Level: len(m[1]) / 2, Ordered: true, Text: markdownInline(strings.TrimSpace(line[len(m[0]):]))})
+			continue
+		}
+		if text := strings.TrimSpace(markdownInline(line)); text != "" {
+			add(Block{Type: BlockParagraph, Text: text})
+		}
+	}
+	flushTable()
+	return doc
+}
+
+// markdownInline 去掉行内的Markdown标记，链接保留文字和地址
+func markdownInline(text string) string {
+	text = mdImagePattern.ReplaceAllString(text, "$1")
+	text = mdLinkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdLinkPattern.FindStringSubmatch(s)
+		if m[1] == m[2] || strings.HasPrefix(m[2], "#") {
+			return m[1]
+		}
+		return fmt.Sprintf("%s (%s)", m[1], strings.TrimPrefix(m[2], "mailto:"))
+	})
+	text = mdAutoLinkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return strings.TrimPrefix(strings.Trim(s, "<>"), "mailto:")
+	})
+	text = mdTagPattern.ReplaceAllString(text, "")
+	text = mdCodePattern.ReplaceAllString(text, "$1")
+	text = mdStrongPattern.ReplaceAllString(text, "$1$2")
+	text = mdEmphasisPattern.ReplaceAllString(text, "$1")
+	text = mdStrikePattern.ReplaceAllString(text, "$1")
+	text = mdEscapePattern.ReplaceAllString(text, "$1")
+	return text
+}
+
+// htmlText 将HTML文档树转换为文档块
+type htmlText struct {
+	blocks []Block
+	line   strings.Builder
+	kind   BlockType // 当前块的类型，为空表示段落
+	level  int
+	order  bool
+	lists  []bool // 列表嵌套栈，记录每层是否为有序列表
+}
+
+// htmlDocument 提取HTML正文，标题、段落和列表项各自成块，表格见 tableBuilder
+func htmlDocument(text string) (*Document, error) {
+	root, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %v", err)
+	}
+	t := &htmlText{}
+	t.walk(root)
+	t.flush()
+	return &Document{Blocks: t.blocks}, nil
+}
+
+// walk 深度优先遍历节点
+func (t *htmlText) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		t.line.WriteString(htmlSpacePattern.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+		switch n.DataAtom {
+		case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg:
+			return
+		case atom.Br:
+			t.flush()
+			return
+		case atom.Pre:
+			t.flush()
+			for _, line := range strings.Split(htmlNodeText(n), "\n") {
+				if strings.TrimSpace(line) != "" {
+					t.blocks = append(t.blocks, Block{Type: BlockParagraph, Text: strings.TrimRight(line, " \t")})
+				}
+			}
+			return
+		case atom.Table:
+			t.flush()
+			t.blocks = append(t.blocks, t.table(n)...)
+			return
+		}
+	}
+
+	block := n.Type == html.ElementNode && isHTMLBlock(n.DataAtom)
+	if block {
+		t.flush()
+	}
+	switch n.DataAtom {
+	case atom.Ul, atom.Ol:
+		t.lists = append(t.lists, n.DataAtom == atom.Ol)
+	case atom.Li:
+		t.kind, t.level, t.order = BlockListItem, 0, false
+		if len(t.lists) > 0 {
+			t.level, t.order = len(t.lists)-1, t.lists[len(t.lists)-1]
+		}
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		t.kind, t.level = BlockHeading, int(n.Data[1]-'0')
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		t.walk(c)
+	}
+
+	switch n.DataAtom {
+	case atom.Ul, atom.Ol:
+		t.lists = t.lists[:len(t.lists)-1]
+	case atom.A:
+		// 链接文字与地址不同时附上地址，如个人主页、GitHub
+		for _, attr := range n.Attr {
+			if attr.Key == "href" && (strings.HasPrefix(attr.Val, "http://") || strings.HasPrefix(attr.Val, "https://")) &&
+				strings.TrimSpace(htmlNodeText(n)) != attr.Val {
+				t.line.WriteString(" (" + attr.Val + ")")
+			}
+		}
+	}
+	if block {
+		t.flush()
+		t.kind, t.level, t.order = "", 0, false
+	}
+}
+
+// table 读取表格，每个单元格单独遍历后交给 tableBuilder 判断是数据行还是排版用的行
+func (t *htmlText) table(n *html.Node) []Block {
+	tb := &tableBuilder{}
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.DataAtom {
+			case atom.Tr:
+				var cells [][]Block
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+						continue
+					}
+					sub := &htmlText{}
+					for cc := cell.FirstChild; cc != nil; cc = cc.NextSibling {
+						sub.walk(cc)
+					}
+					sub.flush()
+					cells = append(cells, sub.blocks)
+				}
+				tb.addRow(cells)
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				visit(c)
+			}
+		}
+	}
+	visit(n)
+	return tb.finish()
+}
+
+// flush 结束当前块，块内的文字为空时保留当前块的类型
+func (t *htmlText) flush() {
+	text := strings.TrimSpace(t.line.String())
+	t.line.Reset()
+	if text == "" {
+		return
+	}
+	kind := t.kind
+	if kind == "" {
+		kind = BlockParagraph
+	}
+	t.blocks = append(t.blocks, Block{Type: kind, Level: t.level, Ordered: t.order, Text: text})
+	t.kind, t.level, t.order = "", 0, false
+}
+
+// isHTMLBlock 判断元素是否为块级元素
+func isHTMLBlock(a atom.Atom) bool {
+	switch a {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Aside, atom.Nav,
+		atom.Ul, atom.Ol, atom.Li, atom.Dl, atom.Dt, atom.Dd, atom.Blockquote,
+		atom.Hr, atom.Address, atom.Figure, atom.Figcaption, atom.Form, atom.Fieldset:
+		return true
+	}
+	return false
+}
+
+// htmlNodeText 返回节点下的全部文字
+func htmlNodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(htmlNodeText(c))
+	}
+	return b.String()
+}